@@ -0,0 +1,301 @@
+package jsondiffprinter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpatch"
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+// Action is the change planned for a block, e.g. a Terraform resource.
+// The values correspond to the actions of a resource change in the JSON
+// representation of a Terraform plan joined with ",".
+type Action string
+
+const (
+	ActionNoOp             Action = "no-op"
+	ActionCreate           Action = "create"
+	ActionRead             Action = "read"
+	ActionUpdate           Action = "update"
+	ActionDelete           Action = "delete"
+	ActionDeleteThenCreate Action = "delete,create"
+	ActionCreateThenDelete Action = "create,delete"
+)
+
+// Description returns the description of the action as used by Terraform in
+// the comment above a resource, e.g. "will be updated in-place".
+func (a Action) Description() string {
+	switch a {
+	case ActionCreate:
+		return "will be created"
+	case ActionRead:
+		return "will be read during apply"
+	case ActionUpdate:
+		return "will be updated in-place"
+	case ActionDelete:
+		return "will be destroyed"
+	case ActionDeleteThenCreate, ActionCreateThenDelete:
+		return "must be replaced"
+	default:
+		return ""
+	}
+}
+
+// Block describes the named block enclosing the diff, e.g. a Terraform
+// resource, which is printed as:
+//
+//	# aws_instance.web will be updated in-place
+//	~ resource "aws_instance" "web" {
+type Block struct {
+	// Address is printed in the comment above the block together with the
+	// description of the action. If empty, the comment is omitted.
+	Address string
+	// Type is the type of the block, e.g. "resource" or "data".
+	Type string
+	// Labels are the quoted labels following the type, e.g. "aws_instance"
+	// and "web".
+	Labels []string
+	// Action is the planned change, which defines the symbol in front of the
+	// block.
+	Action Action
+}
+
+// key returns the part of the block header preceding the left bracket.
+func (b Block) key() string {
+	sb := strings.Builder{}
+	sb.WriteString(b.Type)
+	for _, label := range b.Labels {
+		sb.WriteString(" ")
+		sb.WriteString(quoteHCL(label))
+	}
+	sb.WriteString(" ")
+	return sb.String()
+}
+
+// printBlockComment prints the comment above the block, e.g.
+// "# aws_instance.web will be updated in-place".
+func (f formatter) printBlockComment() {
+	if f.block == nil || f.block.Address == "" {
+		return
+	}
+
+	comment := "# " + f.block.Address
+	if description := f.block.Action.Description(); description != "" {
+		comment += " " + description
+	}
+	fmt.Fprintf(f.w, "%s%s\n", f.prefix, comment)
+}
+
+// actionIndicator returns the symbol for the action, right aligned to the
+// width of the widest symbol.
+func (f formatter) actionIndicator(action Action) string {
+	switch action {
+	case ActionCreate:
+		return "  " + f.c.green("+")
+	case ActionRead:
+		return " " + f.c.cyan("<=")
+	case ActionUpdate:
+		return "  " + f.c.yellow("~")
+	case ActionDelete:
+		return "  " + f.c.red("-")
+	case ActionDeleteThenCreate:
+		return f.c.red("-") + "/" + f.c.green("+")
+	case ActionCreateThenDelete:
+		return f.c.green("+") + "/" + f.c.red("-")
+	default:
+		return "   "
+	}
+}
+
+func (f formatter) isBlockPath(path jsonpointer.Pointer) bool {
+	for _, pattern := range f.blockPaths {
+		if path.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockElements returns the elements of v, if v is an array of objects
+// located at one of the block paths.
+func (f formatter) blockElements(v any, path jsonpointer.Pointer) ([]any, bool) {
+	elements, ok := v.([]any)
+	if !ok || len(path) == 0 || !f.isBlockPath(path) {
+		return nil, false
+	}
+	for _, element := range elements {
+		if _, ok := element.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return elements, true
+}
+
+// isBlockSeries returns true, if all the elements of the array located at
+// path are objects before and after the changes of the patch series.
+func isBlockSeries(patch jsonpatch.Patch, path jsonpointer.Pointer) bool {
+	for _, op := range patch {
+		if !path.IsAncestorOf(op.Path) {
+			break
+		}
+		if !path.IsParentOf(op.Path) {
+			continue
+		}
+
+		var values []any
+		switch op.Operation {
+		case jsonpatch.OperationTest, jsonpatch.OperationAdd:
+			values = []any{op.Value}
+		case jsonpatch.OperationRemove:
+			values = []any{op.OldValue}
+		case jsonpatch.OperationReplace:
+			values = []any{op.OldValue, op.Value}
+		}
+		for _, v := range values {
+			if _, ok := v.(map[string]any); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// blockOperations splits the operation adding or removing an array of objects
+// located at one of the block paths into one operation per block.
+func (f formatter) blockOperations(op jsonpatch.Operation) (jsonpatch.Patch, bool) {
+	var value any
+	switch op.Operation {
+	case jsonpatch.OperationAdd:
+		value = op.Value
+	case jsonpatch.OperationRemove:
+		value = op.OldValue
+	default:
+		return nil, false
+	}
+	elements, ok := f.blockElements(value, op.Path)
+	if !ok {
+		return nil, false
+	}
+
+	ops := make(jsonpatch.Patch, 0, len(elements))
+	for i, element := range elements {
+		blockOp := jsonpatch.Operation{
+			Operation: op.Operation,
+			Path:      op.Path.AppendIndex(i),
+		}
+		if op.Operation == jsonpatch.OperationRemove {
+			blockOp.OldValue = element
+		} else {
+			blockOp.Value = element
+		}
+		ops = append(ops, blockOp)
+	}
+	return ops, true
+}
+
+// printBlocks prints the elements of the array located at path as repeated
+// blocks named after the key of the array. It returns the number of
+// processed operations, whether any of the blocks has changed and the number
+// of hidden unchanged blocks.
+func (f formatter) printBlocks(patch jsonpatch.Patch, path jsonpointer.Pointer, preDiffMarkerIndent, indent string) (int, bool, int) {
+	var i int
+	var hasChange bool
+	var unchangedBlocks int
+
+	cfg := printOpConfig{
+		preDiffMarkerIndent: preDiffMarkerIndent,
+		indent:              indent,
+		key:                 path[len(path)-1] + " ",
+		withKey:             true,
+	}
+	printBlock := func(op jsonpatch.Operation, v any) {
+		cfg.op = op
+		cfg.valType = valueTypePlain
+		cfg.value = f.formatIndent(v, op.Path, f.indentFor(path), f.opTypeIndicator(op.Operation))
+		f.printOp(cfg)
+		fmt.Fprintln(f.w)
+	}
+
+	for i = 0; i < len(patch); i++ {
+		op := patch[i].Clone()
+		if !path.IsParentOf(op.Path) {
+			break
+		}
+
+		switch op.Operation {
+		case jsonpatch.OperationTest:
+			buf := &bytes.Buffer{}
+			fNew := f
+			fNew.w = buf
+			fNew.depthOffset++
+
+			ii, changed := fNew.printPatch(patch[i+1:], op.Path, false)
+			i += ii
+
+			if !changed {
+				if f.hideUnchanged {
+					unchangedBlocks++
+					continue
+				}
+			} else if op.Metadata["operationOverride"] == "" {
+				op.Metadata["operationOverride"] = string(jsonpatch.OperationReplace)
+			}
+
+			hasChange = hasChange || changed
+			cfg.op = op
+			cfg.valType = valueTypeObject
+			cfg.value = buf.String()
+			f.printOp(cfg)
+			fmt.Fprintln(f.w)
+
+		case jsonpatch.OperationAdd:
+			hasChange = true
+			printBlock(op, op.Value)
+
+		case jsonpatch.OperationRemove:
+			hasChange = true
+			printBlock(op, op.OldValue)
+
+		case jsonpatch.OperationReplace:
+			// A replaced block is printed as removed block followed by the
+			// added block.
+			hasChange = true
+			removeOp := op.Clone()
+			removeOp.Operation = jsonpatch.OperationRemove
+			printBlock(removeOp, op.OldValue)
+			addOp := op.Clone()
+			addOp.Operation = jsonpatch.OperationAdd
+			printBlock(addOp, op.Value)
+		}
+	}
+
+	return i, hasChange, unchangedBlocks
+}
+
+// formatBlocks formats the elements of an array of objects located at one of
+// the block paths as repeated blocks with the given key. The result is
+// formatted like the members of an object by formatIndent.
+func (f formatter) formatBlocks(elements []any, key string, path jsonpointer.Pointer, prefix string, operation string) string {
+	sb := strings.Builder{}
+	for i, element := range elements {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if !f.indentedDiffMarkers {
+			sb.WriteString(operation)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(f.prefix + prefix)
+		sb.WriteString(f.indentation)
+		if f.indentedDiffMarkers {
+			sb.WriteString(operation)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(key)
+		sb.WriteString(" ")
+		sb.WriteString(f.formatIndent(element, path.AppendIndex(i), prefix+f.indentation, operation))
+	}
+	return sb.String()
+}
@@ -3,11 +3,17 @@ module github.com/breml/jsondiffprinter/cmd
 go 1.22.3
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/breml/jsondiffprinter v0.0.11
+	github.com/evanphx/json-patch/v5 v5.9.0
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/qri-io/jsonpointer v0.1.1
 	github.com/snorwin/jsonpatch v1.5.0
+	github.com/tailscale/hujson v0.0.0-20221223112325-20486734a56a
 	github.com/wI2L/jsondiff v0.6.1
+	golang.org/x/term v0.29.0
 	golang.org/x/tools v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -19,6 +25,7 @@ require (
 	github.com/stretchr/testify v1.9.0 // indirect
 	github.com/xeipuuv/gojsonpointer v0.0.0-20190905194746-02993c407bfb // indirect
 	github.com/xrash/smetrics v0.0.0-20240521201337-686a1a2994c1 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
 )
 
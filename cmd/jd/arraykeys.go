@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+// alignArrays aligns the arrays in before and after according to the array
+// keys and returns the documents together with their updated JSON
+// representation.
+func (a App) alignArrays(before, after any, beforeJSON, afterJSON []byte) (any, any, []byte, []byte, error) {
+	if len(a.arrayKeys) == 0 {
+		return before, after, beforeJSON, afterJSON, nil
+	}
+
+	before, after = alignArrays(before, after, a.arrayKeys)
+
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	afterJSON, err = json.Marshal(after)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return before, after, beforeJSON, afterJSON, nil
+}
+
+// alignArrays reorders the elements of the arrays in before and after, which
+// are located at a path matching one of the patterns in arrayKeys, such that
+// elements with the same key (a JSON pointer relative to the element) are at
+// the same position. Elements without a counterpart on the other side are
+// moved to the end. This way, the patch libraries compare the matching
+// elements with each other instead of the elements at the same index.
+func alignArrays(before, after any, arrayKeys map[string]string) (any, any) {
+	rules := make(map[string]jsonpointer.Pointer, len(arrayKeys))
+	for pattern, key := range arrayKeys {
+		rules[pattern] = jsonpointer.NewPointerFromPath(key)
+	}
+
+	return alignValue(before, after, jsonpointer.NewPointer(), rules)
+}
+
+func alignValue(before, after any, path jsonpointer.Pointer, rules map[string]jsonpointer.Pointer) (any, any) {
+	switch bt := before.(type) {
+	case map[string]any:
+		at, ok := after.(map[string]any)
+		if !ok {
+			return before, after
+		}
+		for k, bv := range bt {
+			if av, ok := at[k]; ok {
+				bt[k], at[k] = alignValue(bv, av, path.AppendKey(k), rules)
+			}
+		}
+		return bt, at
+
+	case []any:
+		at, ok := after.([]any)
+		if !ok {
+			return before, after
+		}
+		if key, ok := arrayKey(path, rules); ok {
+			bt, at = alignByKey(bt, at, key)
+		}
+		for i := range min(len(bt), len(at)) {
+			bt[i], at[i] = alignValue(bt[i], at[i], path.AppendIndex(i), rules)
+		}
+		return bt, at
+
+	default:
+		return before, after
+	}
+}
+
+func arrayKey(path jsonpointer.Pointer, rules map[string]jsonpointer.Pointer) (jsonpointer.Pointer, bool) {
+	for pattern, key := range rules {
+		if path.Matches(jsonpointer.NewPointerFromPath(pattern)) {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// alignByKey returns before and after with the elements, which have a
+// counterpart with the same key on the other side, first (in the order of
+// before), followed by the remaining elements in their original order.
+func alignByKey(before, after []any, key jsonpointer.Pointer) ([]any, []any) {
+	positions := make(map[string][]int, len(after))
+	for i, v := range after {
+		if k, ok := elementKey(v, key); ok {
+			positions[k] = append(positions[k], i)
+		}
+	}
+
+	alignedBefore := make([]any, 0, len(before))
+	alignedAfter := make([]any, 0, len(after))
+	var unmatchedBefore []any
+	usedAfter := make([]bool, len(after))
+	for _, v := range before {
+		k, ok := elementKey(v, key)
+		if !ok || len(positions[k]) == 0 {
+			unmatchedBefore = append(unmatchedBefore, v)
+			continue
+		}
+		i := positions[k][0]
+		positions[k] = positions[k][1:]
+		alignedBefore = append(alignedBefore, v)
+		alignedAfter = append(alignedAfter, after[i])
+		usedAfter[i] = true
+	}
+	alignedBefore = append(alignedBefore, unmatchedBefore...)
+	for i, v := range after {
+		if !usedAfter[i] {
+			alignedAfter = append(alignedAfter, v)
+		}
+	}
+
+	return alignedBefore, alignedAfter
+}
+
+// elementKey returns the canonical JSON representation of the value located
+// at key in the element v.
+func elementKey(v any, key jsonpointer.Pointer) (string, bool) {
+	for _, token := range key {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		v, ok = m[token]
+		if !ok {
+			return "", false
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
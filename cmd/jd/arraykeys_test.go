@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestAlignByKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:   "same order",
+			before: `[{"name": "a"}, {"name": "b"}]`,
+			after:  `[{"name": "a"}, {"name": "b", "v": 2}]`,
+
+			wantBefore: `[{"name": "a"}, {"name": "b"}]`,
+			wantAfter:  `[{"name": "a"}, {"name": "b", "v": 2}]`,
+		},
+		{
+			name:   "reordered",
+			before: `[{"name": "a"}, {"name": "b"}, {"name": "c"}]`,
+			after:  `[{"name": "c"}, {"name": "a"}, {"name": "b"}]`,
+
+			wantBefore: `[{"name": "a"}, {"name": "b"}, {"name": "c"}]`,
+			wantAfter:  `[{"name": "a"}, {"name": "b"}, {"name": "c"}]`,
+		},
+		{
+			name:   "unmatched elements",
+			before: `[{"name": "a"}, {"name": "b"}, {"name": "c"}]`,
+			after:  `[{"name": "d"}, {"name": "b"}, {"name": "a"}]`,
+
+			wantBefore: `[{"name": "a"}, {"name": "b"}, {"name": "c"}]`,
+			wantAfter:  `[{"name": "a"}, {"name": "b"}, {"name": "d"}]`,
+		},
+		{
+			name:   "inserted element",
+			before: `[{"name": "a"}, {"name": "b"}]`,
+			after:  `[{"name": "new"}, {"name": "a"}, {"name": "b"}]`,
+
+			wantBefore: `[{"name": "a"}, {"name": "b"}]`,
+			wantAfter:  `[{"name": "a"}, {"name": "b"}, {"name": "new"}]`,
+		},
+		{
+			name:   "missing keys",
+			before: `[{"name": "a"}, {"other": 1}, 1]`,
+			after:  `[1, {"other": 1}, {"name": "a"}]`,
+
+			wantBefore: `[{"name": "a"}, {"other": 1}, 1]`,
+			wantAfter:  `[{"name": "a"}, 1, {"other": 1}]`,
+		},
+		{
+			name:   "duplicate keys",
+			before: `[{"name": "a", "v": 1}, {"name": "b"}, {"name": "a", "v": 2}]`,
+			after:  `[{"name": "a", "v": 3}, {"name": "b"}]`,
+
+			wantBefore: `[{"name": "a", "v": 1}, {"name": "b"}, {"name": "a", "v": 2}]`,
+			wantAfter:  `[{"name": "a", "v": 3}, {"name": "b"}]`,
+		},
+		{
+			name:   "duplicate keys on both sides",
+			before: `[{"name": "a", "v": 1}, {"name": "a", "v": 2}]`,
+			after:  `[{"name": "b"}, {"name": "a", "v": 3}, {"name": "a", "v": 4}]`,
+
+			wantBefore: `[{"name": "a", "v": 1}, {"name": "a", "v": 2}]`,
+			wantAfter:  `[{"name": "a", "v": 3}, {"name": "a", "v": 4}, {"name": "b"}]`,
+		},
+		{
+			name:   "keys of different type",
+			before: `[{"name": 1}, {"name": "1"}]`,
+			after:  `[{"name": "1"}, {"name": 1}]`,
+
+			wantBefore: `[{"name": 1}, {"name": "1"}]`,
+			wantAfter:  `[{"name": 1}, {"name": "1"}]`,
+		},
+		{
+			name:   "empty",
+			before: `[]`,
+			after:  `[{"name": "a"}]`,
+
+			wantBefore: `[]`,
+			wantAfter:  `[{"name": "a"}]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotBefore, gotAfter := alignByKey(decodeArray(t, tc.before), decodeArray(t, tc.after), jsonpointer.NewPointerFromPath("/name"))
+
+			require.Equal(t, decodeArray(t, tc.wantBefore), gotBefore)
+			require.Equal(t, decodeArray(t, tc.wantAfter), gotAfter)
+		})
+	}
+}
+
+func TestAlignArrays(t *testing.T) {
+	before := decodeArray(t, `[{"containers": [{"name": "a", "image": "a:1"}, {"name": "b", "image": "b:1"}], "ports": [1, 2]}]`)
+	after := decodeArray(t, `[{"containers": [{"name": "b", "image": "b:2"}, {"name": "a", "image": "a:1"}], "ports": [2, 1]}]`)
+
+	gotBefore, gotAfter := alignArrays(before, after, map[string]string{"/*/containers": "/name"})
+
+	require.Equal(t, decodeArray(t, `[{"containers": [{"name": "a", "image": "a:1"}, {"name": "b", "image": "b:1"}], "ports": [1, 2]}]`), gotBefore)
+	require.Equal(t, decodeArray(t, `[{"containers": [{"name": "a", "image": "a:1"}, {"name": "b", "image": "b:2"}], "ports": [2, 1]}]`), gotAfter)
+}
+
+func decodeArray(t *testing.T, s string) []any {
+	t.Helper()
+
+	var v []any
+	err := json.Unmarshal([]byte(s), &v)
+	require.NoError(t, err)
+	return v
+}
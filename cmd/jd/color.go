@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const colorModes = "auto, always, never"
+
+// colorEnabled reports whether the output should be colorful. In mode auto,
+// color is enabled if stdout is a terminal. The environment variables
+// NO_COLOR (https://no-color.org/) and FORCE_COLOR are respected in mode auto.
+func (a App) colorEnabled() bool {
+	switch strings.ToLower(a.color) {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if forceColor := os.Getenv("FORCE_COLOR"); forceColor != "" && forceColor != "0" && forceColor != "false" {
+		return true
+	}
+
+	return isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
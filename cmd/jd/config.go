@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const configFilename = ".jd.yaml"
+
+// config is the content of the configuration file. It provides the defaults
+// for the command line flags as well as overrides for files matching glob
+// patterns.
+type config struct {
+	configOptions `yaml:",inline"`
+
+	Overrides []configOverride `yaml:"overrides"`
+}
+
+type configOverride struct {
+	// Files contains the glob patterns of the files the override applies to.
+	Files []string `yaml:"files"`
+
+	configOptions `yaml:",inline"`
+}
+
+type configOptions struct {
+	Format        *string           `yaml:"format"`
+	PatchLib      *string           `yaml:"patchlib"`
+	Color         *string           `yaml:"color"`
+	HideUnchanged *bool             `yaml:"hide-unchanged"`
+	Ignore        []string          `yaml:"ignore"`
+	ArrayKeys     map[string]string `yaml:"array-keys"`
+	Sensitive     []string          `yaml:"sensitive"`
+}
+
+// loadConfig loads the configuration before the command is executed.
+// Options, that are explicitly set on the command line, take precedence
+// over the options from the configuration file.
+func (a *App) loadConfig(ctx *cli.Context) error {
+	a.setFlags = make(map[string]bool)
+	for _, name := range []string{"format", "patchlib", "color", "hide-unchanged"} {
+		a.setFlags[name] = isSet(ctx, name)
+	}
+	if isSet(ctx, "c") {
+		a.setFlags["color"] = true
+	}
+
+	var err error
+	a.ignore = a.ignoreFlag.Value()
+	a.sensitive = a.sensitiveFlag.Value()
+	a.arrayKeys, err = parseArrayKeys(a.arrayKeyFlag.Value())
+	if err != nil {
+		return err
+	}
+
+	filename := a.configFile
+	if filename == "" {
+		filename = findConfigFile()
+	}
+	if filename == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	err = yaml.Unmarshal(data, &a.config)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", filename, err)
+	}
+
+	return a.applyConfigOptions(a.config.configOptions)
+}
+
+// findConfigFile returns the path of the configuration file. The file
+// .jd.yaml in the root of the Git repository, the current working directory
+// is located in, takes precedence over $XDG_CONFIG_HOME/jd/config.yaml,
+// which in turn takes precedence over config.yaml in the user's
+// configuration directory, e.g. ~/Library/Application Support/jd on macOS.
+func findConfigFile() string {
+	if dir, err := os.Getwd(); err == nil {
+		for {
+			if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+				if fileExists(filepath.Join(dir, configFilename)) {
+					return filepath.Join(dir, configFilename)
+				}
+				break
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+
+	// os.UserConfigDir only respects XDG_CONFIG_HOME on Unix systems other
+	// than macOS.
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		filename := filepath.Join(dir, "jd", "config.yaml")
+		if fileExists(filename) {
+			return filename
+		}
+	}
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		filename := filepath.Join(dir, "jd", "config.yaml")
+		if fileExists(filename) {
+			return filename
+		}
+	}
+
+	return ""
+}
+
+func fileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// forFile returns a copy of the app with the overrides from the configuration
+// file applied, which match the given file name.
+func (a App) forFile(name string) (App, error) {
+	name = filepath.ToSlash(name)
+	for _, override := range a.config.Overrides {
+		if !matchAny(override.Files, strings.TrimPrefix(name, "./")) {
+			continue
+		}
+		err := a.applyConfigOptions(override.configOptions)
+		if err != nil {
+			return App{}, err
+		}
+	}
+	return a, nil
+}
+
+// applyConfigOptions applies the options, unless the respective flag is set
+// explicitly on the command line. Path rules are added to the ones given on
+// the command line.
+func (a *App) applyConfigOptions(opts configOptions) error {
+	if opts.Format != nil && !a.setFlags["format"] {
+		if !slices.Contains([]string{"diff", "terraform"}, strings.ToLower(*opts.Format)) {
+			return fmt.Errorf("config: format %q is not allowed, supported values: diff, terraform", *opts.Format)
+		}
+		a.format = *opts.Format
+	}
+	if opts.PatchLib != nil && !a.setFlags["patchlib"] {
+		if !isPatchLib(*opts.PatchLib) {
+			return fmt.Errorf("config: patchlib %q is not allowed, supported values: %s", *opts.PatchLib, strings.Join(patchLibs, ", "))
+		}
+		a.patchLib = *opts.PatchLib
+	}
+	if opts.Color != nil && !a.setFlags["color"] {
+		if !slices.Contains([]string{"auto", "always", "never"}, strings.ToLower(*opts.Color)) {
+			return fmt.Errorf("config: color %q is not allowed, supported values: %s", *opts.Color, colorModes)
+		}
+		a.color = *opts.Color
+	}
+	if opts.HideUnchanged != nil && !a.setFlags["hide-unchanged"] {
+		a.hideUnchanged = *opts.HideUnchanged
+	}
+
+	a.ignore = append(slices.Clip(a.ignore), opts.Ignore...)
+	a.sensitive = append(slices.Clip(a.sensitive), opts.Sensitive...)
+	if len(opts.ArrayKeys) > 0 {
+		// The array keys given on the command line have already been
+		// validated by loadConfig.
+		flagArrayKeys, _ := parseArrayKeys(a.arrayKeyFlag.Value())
+		arrayKeys := make(map[string]string, len(a.arrayKeys)+len(opts.ArrayKeys))
+		for pattern, key := range a.arrayKeys {
+			arrayKeys[pattern] = key
+		}
+		for pattern, key := range opts.ArrayKeys {
+			// Array keys from the command line take precedence.
+			if _, ok := flagArrayKeys[pattern]; ok {
+				continue
+			}
+			arrayKeys[pattern] = key
+		}
+		a.arrayKeys = arrayKeys
+	}
+
+	return nil
+}
+
+// parseArrayKeys parses the array keys given on the command line in the form
+// pattern=key.
+func parseArrayKeys(values []string) (map[string]string, error) {
+	arrayKeys := make(map[string]string, len(values))
+	for _, value := range values {
+		pattern, key, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid array key %q, expected pattern=key", value)
+		}
+		arrayKeys[pattern] = key
+	}
+	return arrayKeys, nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	configFile := `
+format: terraform
+patchlib: wI2L
+hide-unchanged: true
+ignore: [/config]
+array-keys:
+  /items: /id
+  /spec: /config
+overrides:
+  - files: ["*.tfstate"]
+    format: diff
+    ignore: [/serial]
+  - files: ["deploy/*.json"]
+    color: never
+    hide-unchanged: false
+    array-keys:
+      /spec: /override
+`
+
+	tests := []struct {
+		name     string
+		app      App
+		filename string
+
+		wantFormat        string
+		wantPatchLib      string
+		wantColor         string
+		wantHideUnchanged bool
+		wantIgnore        []string
+		wantArrayKeys     map[string]string
+	}{
+		{
+			name:     "config file",
+			app:      App{format: "diff", patchLib: "mattbaird", color: "auto"},
+			filename: "a.json",
+
+			wantFormat:        "terraform",
+			wantPatchLib:      "wI2L",
+			wantColor:         "auto",
+			wantHideUnchanged: true,
+			wantIgnore:        []string{"/config"},
+			wantArrayKeys:     map[string]string{"/items": "/id", "/spec": "/config"},
+		},
+		{
+			name: "flags take precedence over config file",
+			app: App{
+				format: "diff", patchLib: "herkyl", color: "auto", hideUnchanged: false,
+				setFlags:     map[string]bool{"format": true, "patchlib": true, "hide-unchanged": true},
+				ignore:       []string{"/flag"},
+				arrayKeyFlag: *cli.NewStringSlice("/spec=/flag"),
+				arrayKeys:    map[string]string{"/spec": "/flag"},
+			},
+			filename: "a.json",
+
+			wantFormat:        "diff",
+			wantPatchLib:      "herkyl",
+			wantColor:         "auto",
+			wantHideUnchanged: false,
+			wantIgnore:        []string{"/flag", "/config"},
+			wantArrayKeys:     map[string]string{"/items": "/id", "/spec": "/flag"},
+		},
+		{
+			name:     "override takes precedence over config file",
+			app:      App{format: "diff", patchLib: "mattbaird", color: "auto"},
+			filename: "prod.tfstate",
+
+			wantFormat:        "diff",
+			wantPatchLib:      "wI2L",
+			wantColor:         "auto",
+			wantHideUnchanged: true,
+			wantIgnore:        []string{"/config", "/serial"},
+			wantArrayKeys:     map[string]string{"/items": "/id", "/spec": "/config"},
+		},
+		{
+			name:     "override matching path",
+			app:      App{format: "diff", patchLib: "mattbaird", color: "auto"},
+			filename: "./deploy/app.json",
+
+			wantFormat:        "terraform",
+			wantPatchLib:      "wI2L",
+			wantColor:         "never",
+			wantHideUnchanged: false,
+			wantIgnore:        []string{"/config"},
+			wantArrayKeys:     map[string]string{"/items": "/id", "/spec": "/override"},
+		},
+		{
+			name: "flags take precedence over override",
+			app: App{
+				format: "terraform", patchLib: "mattbaird", color: "always", hideUnchanged: true,
+				setFlags:     map[string]bool{"format": true, "color": true, "hide-unchanged": true},
+				arrayKeyFlag: *cli.NewStringSlice("/spec=/flag"),
+				arrayKeys:    map[string]string{"/spec": "/flag"},
+			},
+			filename: "deploy/app.json",
+
+			wantFormat:        "terraform",
+			wantPatchLib:      "wI2L",
+			wantColor:         "always",
+			wantHideUnchanged: true,
+			wantIgnore:        []string{"/config"},
+			wantArrayKeys:     map[string]string{"/items": "/id", "/spec": "/flag"},
+		},
+		{
+			name:     "override not matching",
+			app:      App{format: "diff", patchLib: "mattbaird", color: "auto"},
+			filename: "other/app.json",
+
+			wantFormat:        "terraform",
+			wantPatchLib:      "wI2L",
+			wantColor:         "auto",
+			wantHideUnchanged: true,
+			wantIgnore:        []string{"/config"},
+			wantArrayKeys:     map[string]string{"/items": "/id", "/spec": "/config"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := tc.app
+			err := yaml.Unmarshal([]byte(configFile), &app.config)
+			require.NoError(t, err)
+
+			err = app.applyConfigOptions(app.config.configOptions)
+			require.NoError(t, err)
+
+			got, err := app.forFile(tc.filename)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantFormat, got.format)
+			require.Equal(t, tc.wantPatchLib, got.patchLib)
+			require.Equal(t, tc.wantColor, got.color)
+			require.Equal(t, tc.wantHideUnchanged, got.hideUnchanged)
+			require.Equal(t, tc.wantIgnore, got.ignore)
+			require.Equal(t, tc.wantArrayKeys, got.arrayKeys)
+		})
+	}
+}
+
+func TestForFileDoesNotModifyApp(t *testing.T) {
+	app := App{format: "diff"}
+	err := yaml.Unmarshal([]byte(`
+ignore: [/config]
+overrides:
+  - files: ["*.json"]
+    format: terraform
+    ignore: [/override]
+`), &app.config)
+	require.NoError(t, err)
+
+	err = app.applyConfigOptions(app.config.configOptions)
+	require.NoError(t, err)
+
+	_, err = app.forFile("a.json")
+	require.NoError(t, err)
+	got, err := app.forFile("b.yaml")
+	require.NoError(t, err)
+
+	require.Equal(t, "diff", got.format)
+	require.Equal(t, []string{"/config"}, got.ignore)
+}
+
+func TestApplyConfigOptionsInvalid(t *testing.T) {
+	invalid := "invalid"
+
+	tests := []struct {
+		name string
+		opts configOptions
+	}{
+		{
+			name: "format",
+			opts: configOptions{Format: &invalid},
+		},
+		{
+			name: "patchlib",
+			opts: configOptions{PatchLib: &invalid},
+		},
+		{
+			name: "color",
+			opts: configOptions{Color: &invalid},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := App{}
+			err := app.applyConfigOptions(tc.opts)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	writeConfig := func(t *testing.T, filename string) {
+		t.Helper()
+
+		err := os.MkdirAll(filepath.Dir(filename), 0o700)
+		require.NoError(t, err)
+		err = os.WriteFile(filename, []byte("format: terraform\n"), 0o600)
+		require.NoError(t, err)
+	}
+
+	t.Run("repository", func(t *testing.T) {
+		repo := t.TempDir()
+		err := os.Mkdir(filepath.Join(repo, ".git"), 0o700)
+		require.NoError(t, err)
+		writeConfig(t, filepath.Join(repo, configFilename))
+		subdir := filepath.Join(repo, "a", "b")
+		err = os.MkdirAll(subdir, 0o700)
+		require.NoError(t, err)
+
+		xdgConfigHome := t.TempDir()
+		writeConfig(t, filepath.Join(xdgConfigHome, "jd", "config.yaml"))
+		t.Setenv("XDG_CONFIG_HOME", xdgConfigHome)
+		chdir(t, subdir)
+
+		require.Equal(t, filepath.Join(repo, configFilename), findConfigFile())
+	})
+
+	t.Run("XDG_CONFIG_HOME", func(t *testing.T) {
+		xdgConfigHome := t.TempDir()
+		writeConfig(t, filepath.Join(xdgConfigHome, "jd", "config.yaml"))
+		t.Setenv("XDG_CONFIG_HOME", xdgConfigHome)
+		chdir(t, t.TempDir())
+
+		require.Equal(t, filepath.Join(xdgConfigHome, "jd", "config.yaml"), findConfigFile())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("AppData", t.TempDir())
+		chdir(t, t.TempDir())
+
+		require.Equal(t, "", findConfigFile())
+	})
+}
+
+// chdir changes the working directory for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tailscale/hujson"
+	"gopkg.in/yaml.v3"
+)
+
+const inputFormats = "auto, json, jsonc, yaml, toml"
+
+// inputFormat returns the format of the named input. If format is "auto", the
+// format is detected based on the file extension, defaulting to JSON.
+func inputFormat(format string, name string) string {
+	format = strings.ToLower(format)
+	if format != "auto" {
+		return format
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jsonc":
+		return "jsonc"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	default:
+		return "json"
+	}
+}
+
+// decodeInput decodes the input in the given format and returns the document
+// in the form produced by encoding/json (map[string]any, []any, string,
+// float64, bool, nil) as well as its JSON representation.
+func decodeInput(in input, format string) (any, []byte, error) {
+	var doc any
+	var err error
+
+	switch inputFormat(format, in.name) {
+	case "json":
+		err = json.Unmarshal(in.data, &doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		return doc, in.data, nil
+
+	case "jsonc":
+		var data []byte
+		data, err = hujson.Standardize(in.data)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = json.Unmarshal(data, &doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		return doc, data, nil
+
+	case "yaml":
+		doc, err = decodeYAML(in.data)
+	case "toml":
+		var m map[string]any
+		err = toml.Unmarshal(in.data, &m)
+		doc = m
+	default:
+		return nil, nil, fmt.Errorf("unsupported input format %q", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return normalizeDocument(doc)
+}
+
+// decodeYAML decodes a YAML stream. If the stream contains multiple
+// documents, they are returned as array.
+func decodeYAML(data []byte) (any, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var docs []any
+	for {
+		var doc any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	switch len(docs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return docs[0], nil
+	default:
+		return docs, nil
+	}
+}
+
+// normalizeDocument converts a decoded document into the form produced by
+// encoding/json and returns it together with its JSON representation.
+func normalizeDocument(doc any) (any, []byte, error) {
+	data, err := json.Marshal(stringKeys(doc))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var normalized any
+	err = json.Unmarshal(data, &normalized)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return normalized, data, nil
+}
+
+// stringKeys converts maps with non-string keys, as produced by the YAML
+// decoder, into maps with string keys.
+func stringKeys(v any) any {
+	switch vt := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(vt))
+		for k, v := range vt {
+			m[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return m
+	case map[string]any:
+		for k, v := range vt {
+			vt[k] = stringKeys(v)
+		}
+		return vt
+	case []any:
+		for i, v := range vt {
+			vt[i] = stringKeys(v)
+		}
+		return vt
+	default:
+		return v
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestInputFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		name   string
+
+		want string
+	}{
+		{format: "auto", name: "a.json", want: "json"},
+		{format: "auto", name: "a.jsonc", want: "jsonc"},
+		{format: "auto", name: "a.JSONC", want: "jsonc"},
+		{format: "auto", name: "a.json5", want: "json"},
+		{format: "auto", name: "a.yaml", want: "yaml"},
+		{format: "auto", name: "a.yml", want: "yaml"},
+		{format: "auto", name: "a.toml", want: "toml"},
+		{format: "auto", name: "-", want: "json"},
+		{format: "auto", name: "a", want: "json"},
+		{format: "YAML", name: "a.json", want: "yaml"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format+" "+tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, inputFormat(tc.format, tc.name))
+		})
+	}
+}
+
+func TestDecodeInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "a.json",
+			data: `{"a": [1, "b", true, null]}`,
+
+			want: map[string]any{"a": []any{1.0, "b", true, nil}},
+		},
+		{
+			name: "a.jsonc",
+			data: "{\n  // comment\n  \"a\": 1, /* comment */\n  \"b\": [1, 2,],\n}",
+
+			want: map[string]any{"a": 1.0, "b": []any{1.0, 2.0}},
+		},
+		{
+			name:   "stdin",
+			format: "jsonc",
+			data:   `{"a": 1,}`,
+
+			want: map[string]any{"a": 1.0},
+		},
+		{
+			name: "a.json5",
+			data: `{a: 1}`,
+
+			wantErr: true,
+		},
+		{
+			name: "a.yaml",
+			data: "a:\n  - 1\n  - b\nc: null\n",
+
+			want: map[string]any{"a": []any{1.0, "b"}, "c": nil},
+		},
+		{
+			name: "non-string keys.yaml",
+			data: "1: a\ntrue: b\n2.5: c\nnested:\n  3: d\n",
+
+			want: map[string]any{"1": "a", "true": "b", "2.5": "c", "nested": map[string]any{"3": "d"}},
+		},
+		{
+			name: "multiple documents.yaml",
+			data: "a: 1\n---\nb: 2\n---\n- 3\n",
+
+			want: []any{map[string]any{"a": 1.0}, map[string]any{"b": 2.0}, []any{3.0}},
+		},
+		{
+			name: "invalid.yaml",
+			data: "a: [1\n",
+
+			wantErr: true,
+		},
+		{
+			name: "a.toml",
+			data: "a = 1\n[b]\nc = \"d\"\n",
+
+			want: map[string]any{"a": 1.0, "b": map[string]any{"c": "d"}},
+		},
+		{
+			name: "invalid.json",
+			data: `{"a": 1,}`,
+
+			wantErr: true,
+		},
+		{
+			name:   "unsupported format",
+			format: "xml",
+			data:   `<a/>`,
+
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			format := tc.format
+			if format == "" {
+				format = "auto"
+			}
+
+			got, gotJSON, err := decodeInput(input{name: tc.name, data: []byte(tc.data)}, format)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.want, got)
+
+			// The JSON representation is used by the patch libraries and
+			// therefore needs to match the document.
+			var gotFromJSON any
+			err = json.Unmarshal(gotJSON, &gotFromJSON)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, gotFromJSON)
+		})
+	}
+}
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+
+		want any
+	}{
+		{
+			name: "empty",
+			data: "",
+
+			want: nil,
+		},
+		{
+			name: "single document",
+			data: "---\na: 1\n",
+
+			want: map[string]any{"a": 1},
+		},
+		{
+			name: "multiple documents",
+			data: "a: 1\n---\n- b\n---\nc\n",
+
+			want: []any{map[string]any{"a": 1}, []any{"b"}, "c"},
+		},
+		{
+			name: "non-string keys",
+			data: "1: a\nb: 2\n",
+
+			want: map[any]any{1: "a", "b": 2},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeYAML([]byte(tc.data))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+// devNull is the file name used by Git for the missing side of created and
+// deleted files.
+const devNull = "/dev/null"
+
+func (a *App) gitDiffCommand() *cli.Command {
+	flags := &App{}
+	return &cli.Command{
+		Name:  "git-diff",
+		Usage: "Show the difference of a file for Git, to be used as external diff driver (GIT_EXTERNAL_DIFF).",
+		Description: `Configure jd as diff driver for JSON files with:
+
+   git config diff.jd.command "jd git-diff"
+   echo '*.json diff=jd' >> .gitattributes`,
+		ArgsUsage: "path old-file old-hex old-mode new-file new-hex new-mode",
+		Before:    a.mergeSubcommandFlags(flags),
+		Action:    a.RunGitDiff,
+		Flags:     append(flags.formatFlags(), flags.patchLibFlag(false), flags.arrayKeysFlag()),
+	}
+}
+
+// RunGitDiff handles the arguments passed by Git to an external diff driver.
+// For renamed files, Git passes two additional arguments, the new path and
+// the rename details.
+func (a *App) RunGitDiff(ctx *cli.Context) error {
+	if ctx.NArg() != 7 && ctx.NArg() != 9 {
+		_ = cli.ShowSubcommandHelp(ctx)
+		return fmt.Errorf("missing arguments, usage: jd git-diff <path> <old-file> <old-hex> <old-mode> <new-file> <new-hex> <new-mode>")
+	}
+
+	args := ctx.Args().Slice()
+	oldPath, oldFile, newFile := args[0], args[1], args[4]
+	newPath := oldPath
+	if len(args) == 9 {
+		newPath = args[7]
+	}
+
+	err := a.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = a.singlePatchLib()
+	if err != nil {
+		return err
+	}
+	app, err := a.forFile(newPath)
+	if err != nil {
+		return err
+	}
+	a = &app
+
+	before, beforeJSON, err := a.readGitFile(oldPath, oldFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", oldPath, err)
+	}
+	after, afterJSON, err := a.readGitFile(newPath, newFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", newPath, err)
+	}
+
+	var patch any
+	switch {
+	case oldFile == devNull:
+		patch = []map[string]any{{"op": "add", "path": "", "value": after}}
+	case newFile == devNull:
+		patch = []map[string]any{{"op": "remove", "path": ""}}
+	default:
+		before, after, beforeJSON, afterJSON, err = a.alignArrays(before, after, beforeJSON, afterJSON)
+		if err != nil {
+			return err
+		}
+		patch, err = computePatchSafe(a.patchLib, before, after, beforeJSON, afterJSON)
+		if err != nil {
+			return fmt.Errorf("failed to calculate JSON patch using %q: %w", a.patchLib, err)
+		}
+	}
+
+	if !a.quiet {
+		fromName, toName := "a/"+oldPath, "b/"+newPath
+		fmt.Fprintf(ctx.App.Writer, "diff --git %s %s\n", fromName, toName)
+		if oldFile == devNull {
+			fromName = devNull
+		}
+		if newFile == devNull {
+			toName = devNull
+		}
+		fmt.Fprintf(ctx.App.Writer, "--- %s\n+++ %s\n", fromName, toName)
+	}
+
+	err = a.formatDiff(ctx.App.Writer, before, patch)
+	// Git considers a non-zero exit status of the diff driver as failure.
+	if errors.Is(err, errDocumentsDiffer) {
+		return nil
+	}
+	return err
+}
+
+// readGitFile reads and decodes the file passed by Git. The path in the
+// repository is used to detect the input format, since Git might pass a
+// temporary file. For /dev/null, no document is returned.
+func (a App) readGitFile(path string, file string) (any, []byte, error) {
+	if file == devNull {
+		return nil, nil, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decodeInput(input{name: path, data: data}, a.inputFormat)
+}
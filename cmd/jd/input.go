@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// stdinName is the file name, which is used to read a document from stdin.
+const stdinName = "-"
+
+// expectedArgs returns the number of positional arguments, that are expected
+// given the --exec-before and --exec-after flags.
+func (a App) expectedArgs() int {
+	n := 2
+	if a.execBefore != "" {
+		n--
+	}
+	if a.execAfter != "" {
+		n--
+	}
+	return n
+}
+
+// input is a document read from a file, stdin or the output of a command.
+type input struct {
+	// name is the file name, "-" for stdin or the command.
+	name string
+	data []byte
+}
+
+// readInputs reads the before and after documents either from the files
+// given as arguments, from stdin or from the output of the commands provided
+// with --exec-before and --exec-after.
+func (a App) readInputs(ctx *cli.Context) (input, input, error) {
+	args := ctx.Args().Slice()
+	if len(args) == 2 && args[0] == stdinName && args[1] == stdinName {
+		return input{}, input{}, fmt.Errorf("stdin can only be used for either before or after")
+	}
+
+	read := func(command string) (input, error) {
+		if command != "" {
+			data, err := execCommand(ctx.Context, command)
+			return input{name: command, data: data}, err
+		}
+
+		name := args[0]
+		args = args[1:]
+		data, err := readInput(ctx, name)
+		return input{name: name, data: data}, err
+	}
+
+	before, err := read(a.execBefore)
+	if err != nil {
+		return input{}, input{}, fmt.Errorf("failed to read before (%s): %w", before.name, err)
+	}
+	after, err := read(a.execAfter)
+	if err != nil {
+		return input{}, input{}, fmt.Errorf("failed to read after (%s): %w", after.name, err)
+	}
+
+	return before, after, nil
+}
+
+// readInput reads the document from the named file. If the name is "-", the
+// document is read from stdin.
+func readInput(ctx *cli.Context, name string) ([]byte, error) {
+	if name == stdinName {
+		return io.ReadAll(ctx.App.Reader)
+	}
+	return os.ReadFile(name)
+}
+
+// execCommand executes the command using the shell of the operating system
+// and returns its output.
+func execCommand(ctx context.Context, command string) ([]byte, error) {
+	cmd := shellCommand(ctx, command)
+
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// shellCommand returns the command to execute the given command line using
+// the shell of the operating system.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
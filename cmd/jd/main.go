@@ -3,8 +3,11 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/urfave/cli/v2"
@@ -39,129 +42,498 @@ var (
 	date    = "unknown"
 )
 
+// Exit codes following the convention of diff and cmp.
+const (
+	exitCodeEqual = iota
+	exitCodeDifferent
+	exitCodeError
+)
+
+// errDocumentsDiffer is returned, if the compared documents are different.
+var errDocumentsDiffer = errors.New("documents differ")
+
 func main() {
-	if err := main0(os.Args); err != nil {
+	err := main0(os.Args)
+	if err != nil && !errors.Is(err, errDocumentsDiffer) {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode returns the exit status for the error returned by main0.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitCodeEqual
+	case errors.Is(err, errDocumentsDiffer):
+		return exitCodeDifferent
+	default:
+		return exitCodeError
 	}
 }
 
 func main0(osArgs []string) error {
-	app := App{}
-
-	cliapp := &cli.App{
-		Name:      "jd",
-		Usage:     "Show the difference between two JSON files.",
-		Args:      true,
-		ArgsUsage: `before.json after.json`,
-		Before: func(ctx *cli.Context) error {
-			if ctx.NArg() != 2 {
-				fmt.Fprintf(ctx.App.ErrWriter, "Error: missing arguments, usage: jd <before.json> <after.json>\n\n")
-				cli.ShowAppHelpAndExit(ctx, 1)
-			}
-			return nil
-		},
+	return run(osArgs, os.Stdout, isTerminal(os.Stdout))
+}
+
+// run runs jd with the given arguments and writes the output to stdout. If
+// stdout is a terminal, the output is buffered in order to pass it to the
+// pager, if it does not fit on the screen.
+func run(osArgs []string, stdout io.Writer, terminal bool) error {
+	app := App{
+		stdout:   stdout,
+		terminal: terminal,
+	}
+
+	cliapp := app.cliApp()
+	cliapp.Writer = stdout
+	if !terminal {
+		return cliapp.Run(osArgs)
+	}
+
+	buf := bytes.Buffer{}
+	cliapp.Writer = &buf
+	err := cliapp.Run(osArgs)
+
+	pageErr := app.page(stdout, buf.Bytes())
+	if pageErr != nil {
+		return errors.Join(err, pageErr)
+	}
+	return err
+}
+
+// cliApp returns the command line application with the flags bound to the
+// app.
+func (a *App) cliApp() *cli.App {
+	return &cli.App{
+		Name:            "jd",
+		Usage:           "Show the difference between two JSON (or YAML, TOML) files.",
+		Description:     "Exit status is 0 if the documents are equal, 1 if they are different and 2 if an error occurred.",
+		Args:            true,
+		ArgsUsage:       "before.json after.json\n\n   Use - to read either before or after from stdin.",
 		HideHelpCommand: true,
-		Action:          app.Run,
-		Flags: []cli.Flag{
+		Action:          a.Run,
+		Commands: []*cli.Command{
+			a.patchCommand(),
+			a.gitDiffCommand(),
+			a.serveCommand(),
+			a.tfplanCommand(),
+		},
+		Flags: append(a.formatFlags(),
+			a.patchLibFlag(true),
+			a.arrayKeysFlag(),
 			&cli.StringFlag{
-				Name:        "format",
-				Aliases:     []string{"f"},
-				Usage:       `output format for the diff. Supported values: diff, terraform`,
-				Value:       "diff",
-				DefaultText: "diff",
-				Destination: &app.format,
-				Action: func(ctx *cli.Context, s string) error {
-					switch strings.ToLower(s) {
-					case "diff", "terraform":
-						return nil
-					default:
-						return fmt.Errorf(`Flag "--format" value %q is not allowed, supported values: diff, terraform`, s)
-					}
-				},
+				Name:        "config",
+				Usage:       "read the configuration from the given file instead of .jd.yaml in the root of the Git repository or $XDG_CONFIG_HOME/jd/config.yaml",
+				EnvVars:     []string{"JD_CONFIG"},
+				Destination: &a.configFile,
+			},
+			&cli.BoolFlag{
+				Name:        "no-pager",
+				Usage:       "do not pipe the output into a pager, even if it does not fit on the screen",
+				Destination: &a.noPager,
+			},
+			&cli.BoolFlag{
+				Name:        "ndjson",
+				Usage:       "compare before and after as newline delimited JSON (JSON Lines), record by record",
+				Destination: &a.ndjson,
+			},
+			&cli.StringFlag{
+				Name:        "key",
+				Usage:       "in ndjson mode, pair the records by the value at the given JSON pointer instead of by line number",
+				Destination: &a.key,
+			},
+			&cli.BoolFlag{
+				Name:        "verify",
+				Usage:       "apply the calculated JSON patch to before and fail, if the result is not equal to after",
+				Destination: &a.verify,
+			},
+			&cli.BoolFlag{
+				Name:        "watch",
+				Usage:       "watch the files and re-render the diff every time one of them changes",
+				Destination: &a.watch,
+			},
+			&cli.BoolFlag{
+				Name:        "recursive",
+				Aliases:     []string{"r"},
+				Usage:       "recursively compare the files in the directories given as before and after",
+				Destination: &a.recursive,
+			},
+			&cli.StringSliceFlag{
+				Name:        "include",
+				Usage:       "in recursive mode, only compare files matching the given glob pattern, can be repeated",
+				Destination: &a.include,
+			},
+			&cli.StringSliceFlag{
+				Name:        "exclude",
+				Usage:       "in recursive mode, skip files matching the given glob pattern, can be repeated",
+				Destination: &a.exclude,
 			},
 			&cli.StringFlag{
-				Name:        "patchlib",
-				Aliases:     []string{"p"},
-				Usage:       `library, that is used to calculate the JSON patch between before and after. Supported values: cameront, herkyl, mattbaird, MianXiang, snorwin, VictorLowther, VictorLowther-paranoid, wI2L`,
-				Value:       "mattbaird",
-				DefaultText: "mattbaird",
-				Destination: &app.patchLib,
+				Name:        "exec-before",
+				Usage:       "read before from the output of the given shell command instead of a file",
+				Destination: &a.execBefore,
+			},
+			&cli.StringFlag{
+				Name:        "exec-after",
+				Usage:       "read after from the output of the given shell command instead of a file",
+				Destination: &a.execAfter,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       `output mode, either the formatted diff or the computed patch as RFC 6902 JSON patch, RFC 7396 JSON merge patch or jsondiffpatch delta. Supported values: ` + outputFormats,
+				Value:       "diff",
+				DefaultText: "diff",
+				Destination: &a.output,
 				Action: func(ctx *cli.Context, s string) error {
 					switch strings.ToLower(s) {
-					case "cameront", "herkyl", "mattbaird", "mianxiang", "snorwin", "victorlowther", "victorlowther-paranoid", "wi2l":
+					case "diff", "patch", "merge-patch", "jsondiffpatch":
 						return nil
 					default:
-						return fmt.Errorf(`Flag "--patchlib" value %q is not allowed, supported values: cameront, herkyl, mattbaird, MianXiang, snorwin, VictorLowther, VictorLowther-paranoid, wI2L`, s)
+						return fmt.Errorf(`Flag "--output" value %q is not allowed, supported values: %s`, s, outputFormats)
 					}
 				},
 			},
-			&cli.BoolFlag{
-				Name:        "json-in-json",
-				Aliases:     []string{"j"},
-				Usage:       "enable json-in-json processing (embeded json)",
-				Destination: &app.jsonInJSON,
+		),
+		CustomAppHelpTemplate: cli.AppHelpTemplate + patchLibAcknowledgments,
+
+		Copyright: "© 2024, Lucas Bremgartner",
+		Version:   fmt.Sprintf("%s (%s, %s)", version, commit, date),
+	}
+}
+
+// patchLibs contains the supported patch libraries.
+var patchLibs = []string{"cameront", "herkyl", "mattbaird", "MianXiang", "snorwin", "VictorLowther", "VictorLowther-paranoid", "wI2L"}
+
+// patchLibAll is the value of --patchlib to compare the results of all the
+// patch libraries.
+const patchLibAll = "all"
+
+// isPatchLib reports whether name is one of the supported patch libraries.
+func isPatchLib(name string) bool {
+	return slices.ContainsFunc(patchLibs, func(lib string) bool {
+		return strings.EqualFold(lib, name)
+	})
+}
+
+// singlePatchLib returns an error, if the patch library is "all", which is
+// only supported when comparing two documents.
+func (a App) singlePatchLib() error {
+	if strings.EqualFold(a.patchLib, patchLibAll) {
+		return fmt.Errorf("patchlib %q is only supported when comparing two documents, supported values: %s", a.patchLib, strings.Join(patchLibs, ", "))
+	}
+	return nil
+}
+
+// patchLibFlag returns the flag to select the patch library. If withAll is
+// true, the value "all" is accepted to compare all the patch libraries.
+func (a *App) patchLibFlag(withAll bool) cli.Flag {
+	supported := strings.Join(patchLibs, ", ")
+	if withAll {
+		supported += ", " + patchLibAll
+	}
+
+	return &cli.StringFlag{
+		Name:        "patchlib",
+		Aliases:     []string{"p"},
+		Usage:       `library, that is used to calculate the JSON patch between before and after. Supported values: ` + supported,
+		Value:       "mattbaird",
+		DefaultText: "mattbaird",
+		Destination: &a.patchLib,
+		Action: func(ctx *cli.Context, s string) error {
+			if !isPatchLib(s) && !(withAll && strings.EqualFold(s, patchLibAll)) {
+				return fmt.Errorf(`Flag "--patchlib" value %q is not allowed, supported values: %s`, s, supported)
+			}
+			return nil
+		},
+	}
+}
+
+func (a *App) arrayKeysFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:        "array-key",
+		Usage:       "compare the elements of the arrays matching the JSON pointer pattern by the value at the given key instead of by index, format: pattern=/key, can be repeated",
+		Destination: &a.arrayKeyFlag,
+	}
+}
+
+// formatFlags returns the flags, which control how the input is read and
+// how the diff is printed. These flags are shared between the commands.
+func (a *App) formatFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "format",
+			Aliases:     []string{"f"},
+			Usage:       `output format for the diff. Supported values: diff, terraform`,
+			Value:       "diff",
+			DefaultText: "diff",
+			Destination: &a.format,
+			Action: func(ctx *cli.Context, s string) error {
+				switch strings.ToLower(s) {
+				case "diff", "terraform":
+					return nil
+				default:
+					return fmt.Errorf(`Flag "--format" value %q is not allowed, supported values: diff, terraform`, s)
+				}
 			},
-			&cli.BoolFlag{
-				Name:        "color",
-				Aliases:     []string{"c"},
-				Usage:       "enable colorful printing",
-				Destination: &app.color,
+		},
+		&cli.StringFlag{
+			Name:        "input-format",
+			Aliases:     []string{"i"},
+			Usage:       `format of the input documents, auto detects the format by file extension. Supported values: ` + inputFormats,
+			Value:       "auto",
+			DefaultText: "auto",
+			Destination: &a.inputFormat,
+			Action: func(ctx *cli.Context, s string) error {
+				switch strings.ToLower(s) {
+				case "auto", "json", "jsonc", "yaml", "toml":
+					return nil
+				default:
+					return fmt.Errorf(`Flag "--input-format" value %q is not allowed, supported values: %s`, s, inputFormats)
+				}
 			},
-			&cli.BoolFlag{
-				Name:        "hide-unchanged",
-				Aliases:     []string{"u"},
-				Usage:       "hide unchanged lines",
-				Destination: &app.hideUnchanged,
+		},
+		&cli.BoolFlag{
+			Name:        "json-in-json",
+			Aliases:     []string{"j"},
+			Usage:       "enable json-in-json processing (embeded json)",
+			Destination: &a.jsonInJSON,
+		},
+		&cli.StringFlag{
+			Name:        "color",
+			Usage:       `colorful printing, auto enables color if stdout is a terminal, respecting NO_COLOR and FORCE_COLOR. Supported values: ` + colorModes,
+			Value:       "auto",
+			DefaultText: "auto",
+			Destination: &a.color,
+			Action: func(ctx *cli.Context, s string) error {
+				switch strings.ToLower(s) {
+				case "auto", "always", "never":
+					return nil
+				default:
+					return fmt.Errorf(`Flag "--color" value %q is not allowed, supported values: %s`, s, colorModes)
+				}
 			},
-			&cli.BoolFlag{
-				Name:        "show-patch",
-				Usage:       "print the calculated patch.",
-				Destination: &app.showPatch,
-				Hidden:      true,
+		},
+		&cli.BoolFlag{
+			Name:  "c",
+			Usage: "shorthand for --color=always",
+			Action: func(ctx *cli.Context, b bool) error {
+				if b {
+					a.color = "always"
+				}
+				return nil
 			},
 		},
-		CustomAppHelpTemplate: cli.AppHelpTemplate + patchLibAcknowledgments,
+		&cli.BoolFlag{
+			Name:        "hide-unchanged",
+			Aliases:     []string{"u"},
+			Usage:       "hide unchanged lines",
+			Destination: &a.hideUnchanged,
+		},
+		&cli.StringSliceFlag{
+			Name:        "ignore",
+			Usage:       "ignore the changes at the paths matching the JSON pointer pattern (* matches one, ** any number of tokens), can be repeated",
+			Destination: &a.ignoreFlag,
+		},
+		&cli.StringSliceFlag{
+			Name:        "sensitive",
+			Usage:       "hide the values at the paths matching the JSON pointer pattern, can be repeated",
+			Destination: &a.sensitiveFlag,
+		},
+		&cli.BoolFlag{
+			Name:        "quiet",
+			Aliases:     []string{"q"},
+			Usage:       "print nothing, only report the result using the exit status",
+			Destination: &a.quiet,
+		},
+	}
+}
 
-		Copyright: "© 2024, Lucas Bremgartner",
-		Version:   fmt.Sprintf("%s (%s, %s)", version, commit, date),
+// mergeSubcommandFlags returns the before func of a subcommand, whose shared
+// flags are bound to sub. The shared flags can be given before or after the
+// name of the subcommand, e.g. jd -f terraform patch or jd patch -f terraform.
+// Since the destination of a flag is reset, when the flags of the subcommand
+// are parsed, the subcommand uses its own destinations and only the flags
+// set for the subcommand override the values of the app. The values of
+// repeatable flags are added to the ones of the app.
+func (a *App) mergeSubcommandFlags(sub *App) cli.BeforeFunc {
+	return func(ctx *cli.Context) error {
+		for _, name := range ctx.LocalFlagNames() {
+			switch name {
+			case "format":
+				a.format = sub.format
+			case "input-format":
+				a.inputFormat = sub.inputFormat
+			case "json-in-json":
+				a.jsonInJSON = sub.jsonInJSON
+			case "color":
+				a.color = sub.color
+			case "c":
+				a.color = "always"
+			case "hide-unchanged":
+				a.hideUnchanged = sub.hideUnchanged
+			case "quiet":
+				a.quiet = sub.quiet
+			case "patchlib":
+				a.patchLib = sub.patchLib
+			case "ignore":
+				a.ignoreFlag = *cli.NewStringSlice(append(a.ignoreFlag.Value(), sub.ignoreFlag.Value()...)...)
+			case "sensitive":
+				a.sensitiveFlag = *cli.NewStringSlice(append(a.sensitiveFlag.Value(), sub.sensitiveFlag.Value()...)...)
+			case "array-key":
+				a.arrayKeyFlag = *cli.NewStringSlice(append(a.arrayKeyFlag.Value(), sub.arrayKeyFlag.Value()...)...)
+			}
+		}
+		return nil
 	}
+}
 
-	return cliapp.Run(osArgs)
+// isSet reports whether the flag is set on the command line, either for the
+// command itself or for one of its parents.
+func isSet(ctx *cli.Context, name string) bool {
+	return slices.ContainsFunc(ctx.Lineage(), func(c *cli.Context) bool {
+		return c.IsSet(name)
+	})
 }
 
 type App struct {
 	format        string
+	inputFormat   string
 	patchLib      string
 	jsonInJSON    bool
-	color         bool
+	color         string
 	hideUnchanged bool
-	showPatch     bool
+	quiet         bool
+	output        string
+	execBefore    string
+	execAfter     string
+	writePatched  string
+	noPager       bool
+	recursive     bool
+	watch         bool
+	verify        bool
+	ndjson        bool
+	key           string
+	addr          string
+	include       cli.StringSlice
+	exclude       cli.StringSlice
+	ignoreFlag    cli.StringSlice
+	sensitiveFlag cli.StringSlice
+	arrayKeyFlag  cli.StringSlice
+
+	// Configuration file and the resulting path rules, see config.go.
+	configFile string
+	config     config
+	setFlags   map[string]bool
+	ignore     []string
+	sensitive  []string
+	arrayKeys  map[string]string
+
+	// stdout is used in watch mode to bypass the buffering for the pager.
+	stdout io.Writer
+	// terminal is true, if stdout is a terminal.
+	terminal bool
 }
 
 func (a *App) Run(ctx *cli.Context) error {
-	beforeJSON, err := os.ReadFile(ctx.Args().Get(0))
+	if ctx.NArg() != a.expectedArgs() {
+		_ = cli.ShowAppHelp(ctx)
+		return fmt.Errorf("missing arguments, usage: jd <before.json> <after.json>")
+	}
+
+	err := a.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if a.key != "" && !a.ndjson {
+		return fmt.Errorf("--key is only supported in ndjson mode")
+	}
+
+	if a.watch {
+		return a.runWatch(ctx)
+	}
+
+	if a.recursive {
+		return a.runRecursive(ctx, ctx.Args().Get(0), ctx.Args().Get(1))
+	}
+
+	beforeInput, afterInput, err := a.readInputs(ctx)
+	if err != nil {
+		return err
+	}
+
+	app, err := a.forFile(afterInput.name)
+	if err != nil {
+		return err
+	}
+
+	return app.diff(ctx.App.Writer, beforeInput, afterInput)
+}
+
+// diff decodes the before and after inputs, computes the patch between them
+// and writes the output to w. If the documents differ, errDocumentsDiffer is
+// returned.
+func (a App) diff(w io.Writer, beforeInput, afterInput input) error {
+	if a.ndjson {
+		return a.diffNDJSON(w, beforeInput, afterInput)
+	}
+
+	before, beforeJSON, err := decodeInput(beforeInput, a.inputFormat)
 	if err != nil {
-		return fmt.Errorf("failed to read before.json: %w", err)
+		return fmt.Errorf("failed to decode before (%s): %w", beforeInput.name, err)
 	}
-	afterJSON, err := os.ReadFile(ctx.Args().Get(1))
+
+	after, afterJSON, err := decodeInput(afterInput, a.inputFormat)
 	if err != nil {
-		return fmt.Errorf("failed to read after.json: %w", err)
+		return fmt.Errorf("failed to decode after (%s): %w", afterInput.name, err)
 	}
 
-	var before, after any
-	err = json.Unmarshal(beforeJSON, &before)
+	return a.diffDocuments(w, before, after, beforeJSON, afterJSON)
+}
+
+// diffDocuments computes the patch between the decoded documents before and
+// after and writes the output to w. If the documents differ,
+// errDocumentsDiffer is returned.
+func (a App) diffDocuments(w io.Writer, before, after any, beforeJSON, afterJSON []byte) error {
+	before, after, beforeJSON, afterJSON, err := a.alignArrays(before, after, beforeJSON, afterJSON)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal before.json: %w", err)
+		return err
 	}
 
-	err = json.Unmarshal(afterJSON, &after)
+	if strings.EqualFold(a.patchLib, patchLibAll) {
+		return a.compareAllPatchLibs(w, before, after, beforeJSON, afterJSON)
+	}
+
+	// The merge patch and the jsondiffpatch delta are computed from the
+	// documents directly.
+	if !a.outputUsesPatch() && !a.verify {
+		return a.writeOutput(w, before, after, beforeJSON, afterJSON, nil)
+	}
+
+	patch, err := computePatchSafe(a.patchLib, before, after, beforeJSON, afterJSON)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal after.json: %w", err)
+		return fmt.Errorf("failed to calculate JSON patch using %q: %w", a.patchLib, err)
+	}
+
+	if a.verify {
+		err = verifyPatch(patch, beforeJSON, after)
+		if err != nil {
+			return fmt.Errorf("verification of the JSON patch calculated using %q failed: %w", a.patchLib, err)
+		}
 	}
 
+	return a.writeOutput(w, before, after, beforeJSON, afterJSON, patch)
+}
+
+// computePatch computes the JSON patch between before and after using the
+// given patch library.
+func computePatch(patchLib string, before, after any, beforeJSON, afterJSON []byte) (any, error) {
 	var patch any
-	switch strings.ToLower(a.patchLib) {
+	var err error
+
+	switch strings.ToLower(patchLib) {
 	case "cameront":
 		patch, err = cameront.MakePatch(before, after)
 	case "herkyl":
@@ -182,16 +554,38 @@ func (a *App) Run(ctx *cli.Context) error {
 		patch, err = victorlowther.Generate(beforeJSON, afterJSON, true)
 	case "wi2l":
 		patch, err = wI2L.Compare(before, after)
+	default:
+		return nil, fmt.Errorf("unsupported patch library %q", patchLib)
 	}
+
+	return patch, err
+}
+
+// formatDiff prints the diff of the patch applied to before to w. If the
+// patch contains changes, errDocumentsDiffer is returned.
+func (a App) formatDiff(w io.Writer, before any, patch any) error {
+	if a.quiet {
+		w = io.Discard
+	}
+
+	result, err := jsondiffprinter.FormatWithResult(before, patch, a.formatOptions(w)...)
 	if err != nil {
-		return fmt.Errorf("failed to calculate JSON patch using %q: %w", a.patchLib, err)
+		return fmt.Errorf("failed to format using format %q: %w", a.format, err)
+	}
+
+	if result.Changed {
+		return errDocumentsDiffer
 	}
 
-	a.printPatch(ctx, patch)
+	return nil
+}
 
+// formatOptions returns the options for the formatter according to the
+// flags and the configuration.
+func (a App) formatOptions(w io.Writer) []jsondiffprinter.Option {
 	options := []jsondiffprinter.Option{
-		jsondiffprinter.WithWriter(ctx.App.Writer),
-		jsondiffprinter.WithColor(a.color),
+		jsondiffprinter.WithWriter(w),
+		jsondiffprinter.WithColor(a.colorEnabled()),
 		jsondiffprinter.WithHideUnchanged(a.hideUnchanged),
 	}
 
@@ -199,125 +593,58 @@ func (a *App) Run(ctx *cli.Context) error {
 		options = append(options, jsondiffprinter.WithJSONinJSONCompare(a.compare))
 	}
 
+	if len(a.ignore) > 0 {
+		options = append(options, jsondiffprinter.WithNormalizers(jsondiffprinter.IgnorePaths(a.ignore...)))
+	}
+
+	for _, pattern := range a.sensitive {
+		// Hide the values nested below the matching paths as well.
+		options = append(options, jsondiffprinter.WithValueRenderer(strings.TrimSuffix(pattern, "/")+"/**", renderSensitive))
+	}
+
 	if strings.ToLower(a.format) == "terraform" {
 		options = append([]jsondiffprinter.Option{jsondiffprinter.WithTerraformDefaults()}, options...)
 	}
 
-	err = jsondiffprinter.Format(before, patch, options...)
-	if err != nil {
-		return fmt.Errorf("failed to format using format %q: %w", a.format, err)
-	}
+	return options
+}
 
-	return nil
+// renderSensitive hides the value.
+func renderSensitive(any) (string, bool) {
+	return "(sensitive value)", true
 }
 
-// TODO: combine with initial JSON Patch calculation in func Run.
+// compare computes the JSON patch between before and after using the
+// configured patch library. It is used to compare JSON documents embedded in
+// JSON strings.
 func (a App) compare(before, after any) ([]byte, error) {
-	var err error
-	var beforeJSON, afterJSON []byte
-
-	jsonMarshal := func() error {
-		beforeJSON, err = json.Marshal(before)
-		if err != nil {
-			return err
-		}
-		afterJSON, err = json.Marshal(after)
-		if err != nil {
-			return err
-		}
-		return nil
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return nil, err
 	}
-
-	var marshal bool
-	var patch any
-	switch strings.ToLower(a.patchLib) {
-	case "cameront":
-		patch, err = cameront.MakePatch(before, after)
-		marshal = true
-	case "herkyl":
-		err = jsonMarshal()
-		if err != nil {
-			break
-		}
-		patch, err = herkyl.Diff(beforeJSON, afterJSON)
-		marshal = true
-	case "mattbaird":
-		err = jsonMarshal()
-		if err != nil {
-			break
-		}
-		patch, err = mattbaird.CreatePatch(beforeJSON, afterJSON)
-		marshal = true
-	case "mianxiang":
-		// TODO: consider options offered by 520MianXiangDuiXiang520/json-diff
-		err = jsonMarshal()
-		if err != nil {
-			break
-		}
-		patch, err = mianxiang.AsDiffs(beforeJSON, afterJSON)
-	case "snorwin":
-		// TODO: add snorwin-threeway
-		var patchList snorwin.JSONPatchList
-		patchList, err = snorwin.CreateJSONPatch(after, before)
-		patch = patchList.Raw()
-	case "victorlowther":
-		err = jsonMarshal()
-		if err != nil {
-			break
-		}
-		patch, err = victorlowther.Generate(beforeJSON, afterJSON, false)
-		marshal = true
-	case "victorlowther-paranoid":
-		err = jsonMarshal()
-		if err != nil {
-			break
-		}
-		patch, err = victorlowther.Generate(beforeJSON, afterJSON, true)
-		marshal = true
-	case "wi2l":
-		patch, err = wI2L.Compare(before, after)
-		marshal = true
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
 	}
+
+	patch, err := computePatchSafe(a.patchLib, before, after, beforeJSON, afterJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate JSON patch using %q: %w", a.patchLib, err)
 	}
 
-	var patchData []byte
-	if marshal {
-		buf := bytes.Buffer{}
-		encoder := json.NewEncoder(&buf)
-		encoder.SetIndent("", "  ")
-		encoder.SetEscapeHTML(false)
-		err = encoder.Encode(patch)
-		if err != nil {
-			return nil, err
-		}
-		patchData = buf.Bytes()
-	} else {
-		patchData = patch.([]byte)
-		patchData = append(patchData, '\n')
+	// Some libraries return the patch already encoded as JSON.
+	if patchData, ok := patch.([]byte); ok {
+		return append(patchData, '\n'), nil
 	}
 
-	return patchData, nil
-}
-
-func (a App) printPatch(c *cli.Context, patch any) {
-	if !a.showPatch {
-		return
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(patch)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Fprintln(c.App.ErrWriter, "--- JSON patch ---")
-	switch p := patch.(type) {
-	case []byte:
-		fmt.Fprintln(c.App.ErrWriter, string(p))
-	default:
-		patchJSON, err := json.MarshalIndent(patch, "", "  ")
-		if err != nil {
-			fmt.Fprintf(c.App.ErrWriter, "error: %v\n", err)
-			return
-		}
-
-		fmt.Fprintln(c.App.ErrWriter, string(patchJSON))
-	}
-	fmt.Fprintln(c.App.ErrWriter, "--- JSON patch ---")
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+// runJD runs jd with the given arguments and returns the output written to
+// stdout. The configuration file in the user's configuration directory is
+// ignored.
+func runJD(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("NO_COLOR", "1")
+
+	app := App{
+		stdout: os.Stdout,
+	}
+	cliapp := app.cliApp()
+	buf := bytes.Buffer{}
+	cliapp.Writer = &buf
+	cliapp.ErrWriter = io.Discard
+
+	err := cliapp.Run(append([]string{"jd"}, args...))
+	return buf.String(), err
+}
+
+// writeFiles writes the files, given by their slash separated paths, to a
+// temporary directory and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(filename), 0o700)
+		require.NoError(t, err)
+		err = os.WriteFile(filename, []byte(content), 0o600)
+		require.NoError(t, err)
+	}
+	return dir
+}
+
+func TestSubcommandFlags(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"original.json": `{"a": 1, "b": 1}`,
+		"patch.json":    `[{"op": "replace", "path": "/a", "value": 2}]`,
+	})
+	original := filepath.Join(dir, "original.json")
+	patch := filepath.Join(dir, "patch.json")
+
+	terraform := `  {
+    ~ a = 1 -> 2
+      # (1 unchanged attribute hidden)
+  }
+`
+
+	tests := []struct {
+		name string
+		args []string
+
+		want string
+	}{
+		{
+			name: "flags before subcommand",
+			args: []string{"-u", "-f", "terraform", "patch", original, patch},
+
+			want: terraform,
+		},
+		{
+			name: "flags after subcommand",
+			args: []string{"patch", "-u", "-f", "terraform", original, patch},
+
+			want: terraform,
+		},
+		{
+			name: "flags before and after subcommand",
+			args: []string{"-f", "terraform", "patch", "-u", original, patch},
+
+			want: terraform,
+		},
+		{
+			name: "subcommand flag takes precedence",
+			args: []string{"-f", "terraform", "patch", "-f", "diff", original, patch},
+
+			want: `  {
+-   "a": 1,
++   "a": 2,
+    "b": 1
+  }
+`,
+		},
+		{
+			name: "repeatable flags are merged",
+			args: []string{"--ignore", "/a", "patch", "--ignore", "/b", original, patch},
+
+			want: `  {
+    "a": 1,
+    "b": 1
+  }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := runJD(t, tc.args...)
+
+			require.EqualStringWithTabwriter(t, tc.want, got)
+		})
+	}
+}
+
+func TestColorFlags(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"before.json": `{"a": 1}`,
+		"after.json":  `{"a": 2}`,
+		"config.yaml": `color: never`,
+	})
+	before := filepath.Join(dir, "before.json")
+	after := filepath.Join(dir, "after.json")
+
+	colored := "  {\n\033[31m-\033[0m   \"a\": 1\n\033[32m+\033[0m   \"a\": 2\n  }\n"
+	plain := "  {\n-   \"a\": 1\n+   \"a\": 2\n  }\n"
+
+	tests := []struct {
+		name string
+		args []string
+
+		want string
+	}{
+		{
+			name: "auto",
+			args: []string{before, after},
+
+			want: plain,
+		},
+		{
+			name: "color always",
+			args: []string{"--color", "always", before, after},
+
+			want: colored,
+		},
+		{
+			name: "shorthand",
+			args: []string{"-c", before, after},
+
+			want: colored,
+		},
+		{
+			name: "shorthand takes precedence over config file",
+			args: []string{"--config", filepath.Join(dir, "config.yaml"), "-c", before, after},
+
+			want: colored,
+		},
+		{
+			name: "shorthand for subcommand",
+			args: []string{"git-diff", "-c", "a.json", before, "1", "100644", after, "2", "100644"},
+
+			want: "diff --git a/a.json b/a.json\n--- a/a.json\n+++ b/a.json\n" + colored,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := runJD(t, tc.args...)
+
+			require.EqualStringWithTabwriter(t, tc.want, got)
+		})
+	}
+}
+
+func TestPatchLibPanic(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"before.json": `{"a": {"b": 1}}`,
+		"after.json":  `{"a": [1]}`,
+	})
+
+	_, err := runJD(t, "-p", "cameront", filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json"))
+	require.Error(t, err)
+	if errors.Is(err, errDocumentsDiffer) {
+		t.Fatalf("want error other than %v, got %v", errDocumentsDiffer, err)
+	}
+	if !strings.Contains(err.Error(), `failed to calculate JSON patch using "cameront": panic:`) {
+		t.Errorf("want error about panic of the patch library, got %v", err)
+	}
+}
+
+func TestPatchLibAll(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"before.json": `{"a": "{\"x\": 1}"}`,
+		"after.json":  `{"a": "{\"x\": 2}"}`,
+	})
+	before := filepath.Join(dir, "before.json")
+	after := filepath.Join(dir, "after.json")
+
+	got, err := runJD(t, "-j", "-p", "all", before, after)
+	if !errors.Is(err, errDocumentsDiffer) {
+		t.Fatalf("want error %v, got %v", errDocumentsDiffer, err)
+	}
+	for _, lib := range patchLibs {
+		if !strings.Contains(got, "=== "+lib+" ===\n") {
+			t.Errorf("want output for %s, got:\n%s", lib, got)
+		}
+	}
+	if strings.Contains(got, "Error:") {
+		t.Errorf("want no errors, got:\n%s", got)
+	}
+}
+
+func TestPatchLibAllNotSupported(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"before.json": `{"a": 1}`,
+		"after.json":  `{"a": 2}`,
+	})
+	before := filepath.Join(dir, "before.json")
+	after := filepath.Join(dir, "after.json")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "git-diff",
+			args: []string{"-p", "all", "git-diff", "a.json", before, "1", "100644", after, "2", "100644"},
+		},
+		{
+			name: "tfplan",
+			args: []string{"-p", "all", "tfplan", filepath.Join("testdata", "tfplan", "plan.json")},
+		},
+		{
+			name: "serve",
+			args: []string{"-p", "all", "serve", "--addr", "127.0.0.1:0"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := runJD(t, tc.args...)
+			require.Error(t, err)
+			if errors.Is(err, errDocumentsDiffer) {
+				t.Fatalf("want error other than %v, got %v", errDocumentsDiffer, err)
+			}
+			require.Equal(t, "", got)
+		})
+	}
+}
+
+func TestComputePatchUnsupported(t *testing.T) {
+	_, err := computePatch("unknown", nil, nil, []byte("null"), []byte("null"))
+	require.Error(t, err)
+	require.Equal(t, `unsupported patch library "unknown"`, err.Error())
+}
+
+func TestExitCode(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.json":       `{"a": 1}`,
+		"a-copy.json":  `{"a": 1}`,
+		"b.json":       `{"a": 2}`,
+		"invalid.json": `{"a": `,
+		"patch.json":   `[{"op": "replace", "path": "/a", "value": 2}]`,
+	})
+	file := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+
+		wantExitCode int
+		wantEmpty    bool
+	}{
+		{
+			name: "equal",
+			args: []string{file("a.json"), file("a-copy.json")},
+
+			wantExitCode: 0,
+		},
+		{
+			name: "different",
+			args: []string{file("a.json"), file("b.json")},
+
+			wantExitCode: 1,
+		},
+		{
+			name: "invalid document",
+			args: []string{file("a.json"), file("invalid.json")},
+
+			wantExitCode: 2,
+			wantEmpty:    true,
+		},
+		{
+			name: "missing file",
+			args: []string{file("a.json"), file("missing.json")},
+
+			wantExitCode: 2,
+			wantEmpty:    true,
+		},
+		{
+			name: "quiet equal",
+			args: []string{"-q", file("a.json"), file("a-copy.json")},
+
+			wantExitCode: 0,
+			wantEmpty:    true,
+		},
+		{
+			name: "quiet different",
+			args: []string{"-q", file("a.json"), file("b.json")},
+
+			wantExitCode: 1,
+			wantEmpty:    true,
+		},
+		{
+			name: "quiet patch output",
+			args: []string{"-q", "-o", "patch", file("a.json"), file("b.json")},
+
+			wantExitCode: 1,
+			wantEmpty:    true,
+		},
+		{
+			name: "quiet all patch libraries",
+			args: []string{"-q", "-p", "all", file("a.json"), file("b.json")},
+
+			wantExitCode: 1,
+			wantEmpty:    true,
+		},
+		{
+			name: "quiet patch subcommand",
+			args: []string{"patch", "-q", file("a.json"), file("patch.json")},
+
+			wantExitCode: 1,
+			wantEmpty:    true,
+		},
+		{
+			// Git considers a non-zero exit status of the diff driver as
+			// failure.
+			name: "quiet git-diff",
+			args: []string{"-q", "git-diff", "a.json", file("a.json"), "1", "100644", file("b.json"), "2", "100644"},
+
+			wantExitCode: 0,
+			wantEmpty:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := runJD(t, tc.args...)
+
+			require.Equal(t, tc.wantExitCode, exitCode(err))
+			if tc.wantExitCode == 1 && !errors.Is(err, errDocumentsDiffer) {
+				t.Errorf("want error %v, got %v", errDocumentsDiffer, err)
+			}
+			if tc.wantEmpty {
+				require.Equal(t, "", got)
+			} else if got == "" {
+				t.Errorf("want output, got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+// record is a single document of a newline delimited JSON stream.
+type record struct {
+	line int
+	doc  any
+	data []byte
+}
+
+// recordPair contains the records of before and after, which are compared
+// with each other. For inserted and deleted records, one of them is nil.
+type recordPair struct {
+	label  string
+	before *record
+	after  *record
+}
+
+// diffNDJSON compares the records of the newline delimited JSON streams
+// before and after and writes the diff of each changed, inserted or deleted
+// record to w, followed by a summary. If the streams differ,
+// errDocumentsDiffer is returned.
+func (a App) diffNDJSON(w io.Writer, beforeInput, afterInput input) error {
+	beforeRecords, err := parseNDJSON(beforeInput.data)
+	if err != nil {
+		return fmt.Errorf("failed to decode before (%s): %w", beforeInput.name, err)
+	}
+	afterRecords, err := parseNDJSON(afterInput.data)
+	if err != nil {
+		return fmt.Errorf("failed to decode after (%s): %w", afterInput.name, err)
+	}
+
+	var pairs []recordPair
+	if a.key != "" {
+		pairs, err = pairByKey(beforeRecords, afterRecords, a.key)
+		if err != nil {
+			return err
+		}
+	} else {
+		pairs = pairByLine(beforeRecords, afterRecords)
+	}
+
+	if a.quiet {
+		w = io.Discard
+	}
+
+	var changed, inserted, deleted, unchanged int
+	for _, pair := range pairs {
+		switch {
+		case pair.before == nil:
+			inserted++
+			fmt.Fprintf(w, "@@ %s (inserted) @@\n", pair.label)
+			patch := []map[string]any{{"op": "add", "path": "", "value": pair.after.doc}}
+			err = a.writeOutput(w, nil, pair.after.doc, []byte("null"), pair.after.data, patch)
+
+		case pair.after == nil:
+			deleted++
+			fmt.Fprintf(w, "@@ %s (deleted) @@\n", pair.label)
+			patch := []map[string]any{{"op": "remove", "path": ""}}
+			err = a.writeOutput(w, pair.before.doc, nil, pair.before.data, []byte("null"), patch)
+
+		case reflect.DeepEqual(pair.before.doc, pair.after.doc):
+			unchanged++
+			continue
+
+		default:
+			changed++
+			fmt.Fprintf(w, "@@ %s @@\n", pair.label)
+			err = a.diffDocuments(w, pair.before.doc, pair.after.doc, pair.before.data, pair.after.data)
+		}
+		if err != nil && !errors.Is(err, errDocumentsDiffer) {
+			return fmt.Errorf("%s: %w", pair.label, err)
+		}
+	}
+
+	fmt.Fprintf(w, "Summary: %d changed, %d inserted, %d deleted, %d unchanged records.\n", changed, inserted, deleted, unchanged)
+
+	if changed+inserted+deleted > 0 {
+		return errDocumentsDiffer
+	}
+	return nil
+}
+
+// parseNDJSON decodes the records of a newline delimited JSON stream. Empty
+// lines are skipped.
+func parseNDJSON(data []byte) ([]record, error) {
+	var records []record
+	for line := 1; len(data) > 0; line++ {
+		var raw []byte
+		raw, data, _ = bytes.Cut(data, []byte("\n"))
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+
+		var doc any
+		err := json.Unmarshal(raw, &doc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record{line: line, doc: doc, data: raw})
+	}
+	return records, nil
+}
+
+// pairByLine pairs the records by their position in the stream, which is
+// the line number, unless the stream contains empty lines.
+func pairByLine(before, after []record) []recordPair {
+	pairs := make([]recordPair, 0, max(len(before), len(after)))
+	for i := 0; i < max(len(before), len(after)); i++ {
+		var pair recordPair
+		if i < len(before) {
+			pair.before = &before[i]
+			pair.label = fmt.Sprintf("line %d", before[i].line)
+		}
+		if i < len(after) {
+			pair.after = &after[i]
+			switch {
+			case pair.before == nil:
+				pair.label = fmt.Sprintf("line %d", after[i].line)
+			case pair.before.line != after[i].line:
+				pair.label += fmt.Sprintf(" -> %d", after[i].line)
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// pairByKey pairs the records by the value located at the JSON pointer key.
+// The pairs are ordered like the records in before, followed by the records
+// only present in after.
+func pairByKey(before, after []record, key string) ([]recordPair, error) {
+	pointer := jsonpointer.NewPointerFromPath(key)
+
+	index := func(records []record, side string) (map[string]int, []string, error) {
+		positions := make(map[string]int, len(records))
+		keys := make([]string, 0, len(records))
+		for i, rec := range records {
+			k, ok := elementKey(rec.doc, pointer)
+			if !ok {
+				return nil, nil, fmt.Errorf("%s line %d: no value at key %q", side, rec.line, key)
+			}
+			if j, ok := positions[k]; ok {
+				return nil, nil, fmt.Errorf("%s line %d: duplicate key %s=%s, already used on line %d", side, rec.line, key, k, records[j].line)
+			}
+			positions[k] = i
+			keys = append(keys, k)
+		}
+		return positions, keys, nil
+	}
+
+	beforePositions, beforeKeys, err := index(before, "before")
+	if err != nil {
+		return nil, err
+	}
+	afterPositions, afterKeys, err := index(after, "after")
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]recordPair, 0, max(len(before), len(after)))
+	for i, k := range beforeKeys {
+		pair := recordPair{
+			label:  key + "=" + k,
+			before: &before[i],
+		}
+		if j, ok := afterPositions[k]; ok {
+			pair.after = &after[j]
+		}
+		pairs = append(pairs, pair)
+	}
+	for j, k := range afterKeys {
+		if _, ok := beforePositions[k]; ok {
+			continue
+		}
+		pairs = append(pairs, recordPair{
+			label: key + "=" + k,
+			after: &after[j],
+		})
+	}
+
+	return pairs, nil
+}
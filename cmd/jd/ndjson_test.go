@@ -0,0 +1,300 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+// pairLines is the simplified form of a record pair for comparison, where
+// the line numbers of missing records are 0.
+type pairLines struct {
+	label  string
+	before int
+	after  int
+}
+
+func toPairLines(pairs []recordPair) []pairLines {
+	lines := make([]pairLines, 0, len(pairs))
+	for _, pair := range pairs {
+		pl := pairLines{label: pair.label}
+		if pair.before != nil {
+			pl.before = pair.before.line
+		}
+		if pair.after != nil {
+			pl.after = pair.after.line
+		}
+		lines = append(lines, pl)
+	}
+	return lines
+}
+
+func mustParseNDJSON(t *testing.T, data string) []record {
+	t.Helper()
+
+	records, err := parseNDJSON([]byte(data))
+	require.NoError(t, err)
+	return records
+}
+
+func TestParseNDJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+
+		wantLines []int
+		wantDocs  []any
+		wantErr   bool
+	}{
+		{
+			name: "empty",
+			data: "",
+		},
+		{
+			name: "records",
+			data: "{\"a\":1}\n[2]\n\"3\"\n",
+
+			wantLines: []int{1, 2, 3},
+			wantDocs:  []any{map[string]any{"a": 1.0}, []any{2.0}, "3"},
+		},
+		{
+			name: "without trailing newline",
+			data: "1\n2",
+
+			wantLines: []int{1, 2},
+			wantDocs:  []any{1.0, 2.0},
+		},
+		{
+			name: "blank lines and whitespace",
+			data: "\n1\n  \n\t2 \r\n\n",
+
+			wantLines: []int{2, 4},
+			wantDocs:  []any{1.0, 2.0},
+		},
+		{
+			name: "invalid record",
+			data: "1\n{\n",
+
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := parseNDJSON([]byte(tc.data))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var lines []int
+			var docs []any
+			for _, rec := range records {
+				lines = append(lines, rec.line)
+				docs = append(docs, rec.doc)
+			}
+			require.Equal(t, tc.wantLines, lines)
+			require.Equal(t, tc.wantDocs, docs)
+		})
+	}
+}
+
+func TestParseNDJSONErrorLine(t *testing.T) {
+	_, err := parseNDJSON([]byte("1\n\n{\n"))
+	require.Error(t, err)
+	require.Equal(t, "line 3: unexpected end of JSON input", err.Error())
+}
+
+func TestPairByLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+
+		want []pairLines
+	}{
+		{
+			name:   "same length",
+			before: "1\n2\n",
+			after:  "1\n3\n",
+
+			want: []pairLines{
+				{label: "line 1", before: 1, after: 1},
+				{label: "line 2", before: 2, after: 2},
+			},
+		},
+		{
+			name:   "inserted",
+			before: "1\n",
+			after:  "1\n2\n3\n",
+
+			want: []pairLines{
+				{label: "line 1", before: 1, after: 1},
+				{label: "line 2", after: 2},
+				{label: "line 3", after: 3},
+			},
+		},
+		{
+			name:   "deleted",
+			before: "1\n2\n",
+			after:  "1\n",
+
+			want: []pairLines{
+				{label: "line 1", before: 1, after: 1},
+				{label: "line 2", before: 2},
+			},
+		},
+		{
+			name:   "inserted in the middle",
+			before: "1\n3\n",
+			after:  "1\n2\n3\n",
+
+			want: []pairLines{
+				{label: "line 1", before: 1, after: 1},
+				{label: "line 2", before: 2, after: 2},
+				{label: "line 3", after: 3},
+			},
+		},
+		{
+			name:   "blank lines shift the labels",
+			before: "1\n\n2\n3\n",
+			after:  "1\n2\n\n\n3\n",
+
+			want: []pairLines{
+				{label: "line 1", before: 1, after: 1},
+				{label: "line 3 -> 2", before: 3, after: 2},
+				{label: "line 4 -> 5", before: 4, after: 5},
+			},
+		},
+		{
+			name:   "blank lines before inserted record",
+			before: "1\n",
+			after:  "1\n\n2\n",
+
+			want: []pairLines{
+				{label: "line 1", before: 1, after: 1},
+				{label: "line 3", after: 3},
+			},
+		},
+		{
+			name:   "empty",
+			before: "",
+			after:  "",
+
+			want: []pairLines{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pairs := pairByLine(mustParseNDJSON(t, tc.before), mustParseNDJSON(t, tc.after))
+
+			require.Equal(t, tc.want, toPairLines(pairs))
+		})
+	}
+}
+
+func TestPairByKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		before string
+		after  string
+
+		want    []pairLines
+		wantErr string
+	}{
+		{
+			name:   "same order",
+			key:    "/id",
+			before: "{\"id\":1,\"v\":1}\n{\"id\":2}\n",
+			after:  "{\"id\":1,\"v\":2}\n{\"id\":2}\n",
+
+			want: []pairLines{
+				{label: "/id=1", before: 1, after: 1},
+				{label: "/id=2", before: 2, after: 2},
+			},
+		},
+		{
+			name:   "reordered",
+			key:    "/id",
+			before: "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n",
+			after:  "{\"id\":3}\n{\"id\":1}\n{\"id\":2}\n",
+
+			want: []pairLines{
+				{label: "/id=1", before: 1, after: 2},
+				{label: "/id=2", before: 2, after: 3},
+				{label: "/id=3", before: 3, after: 1},
+			},
+		},
+		{
+			name:   "inserted and deleted",
+			key:    "/id",
+			before: "{\"id\":\"a\"}\n{\"id\":\"b\"}\n",
+			after:  "{\"id\":\"c\"}\n{\"id\":\"a\"}\n",
+
+			want: []pairLines{
+				{label: `/id="a"`, before: 1, after: 2},
+				{label: `/id="b"`, before: 2},
+				{label: `/id="c"`, after: 1},
+			},
+		},
+		{
+			name:   "nested key",
+			key:    "/meta/name",
+			before: "{\"meta\":{\"name\":\"a\"}}\n",
+			after:  "\n{\"meta\":{\"name\":\"a\"},\"v\":1}\n",
+
+			want: []pairLines{
+				{label: `/meta/name="a"`, before: 1, after: 2},
+			},
+		},
+		{
+			name:   "duplicate key in before",
+			key:    "/id",
+			before: "{\"id\":1}\n\n{\"id\":1}\n",
+			after:  "{\"id\":1}\n",
+
+			wantErr: "before line 3: duplicate key /id=1, already used on line 1",
+		},
+		{
+			name:   "duplicate key in after",
+			key:    "/id",
+			before: "{\"id\":1}\n",
+			after:  "{\"id\":2}\n{\"id\":2}\n",
+
+			wantErr: "after line 2: duplicate key /id=2, already used on line 1",
+		},
+		{
+			name:   "missing key",
+			key:    "/id",
+			before: "{\"id\":1}\n{\"name\":\"a\"}\n",
+			after:  "{\"id\":1}\n",
+
+			wantErr: `before line 2: no value at key "/id"`,
+		},
+		{
+			name:   "record not an object",
+			key:    "/id",
+			before: "{\"id\":1}\n",
+			after:  "[1]\n",
+
+			wantErr: `after line 1: no value at key "/id"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pairs, err := pairByKey(mustParseNDJSON(t, tc.before), mustParseNDJSON(t, tc.after), tc.key)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				require.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.want, toPairLines(pairs))
+		})
+	}
+}
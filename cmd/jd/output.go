@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	evanphx "github.com/evanphx/json-patch/v5"
+)
+
+const outputFormats = "diff, patch, merge-patch, jsondiffpatch"
+
+// writeOutput writes the difference between before and after in the output
+// format selected with --output to w. If the documents differ,
+// errDocumentsDiffer is returned.
+func (a App) writeOutput(w io.Writer, before, after any, beforeJSON, afterJSON []byte, patch any) error {
+	var data []byte
+	var err error
+
+	switch strings.ToLower(a.output) {
+	case "patch":
+		data, err = marshalPatch(patch)
+	case "merge-patch":
+		data, err = mergePatch(before, after, beforeJSON, afterJSON)
+	case "jsondiffpatch":
+		data, err = marshalIndent(jsonDiffPatchDelta(before, after))
+	default:
+		return a.formatDiff(w, before, patch)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create output %q: %w", a.output, err)
+	}
+
+	if !a.quiet {
+		_, err = w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		return errDocumentsDiffer
+	}
+
+	return nil
+}
+
+// outputUsesPatch reports whether the output format selected with --output
+// is based on the JSON patch computed by the patch library.
+func (a App) outputUsesPatch() bool {
+	switch strings.ToLower(a.output) {
+	case "merge-patch", "jsondiffpatch":
+		return false
+	default:
+		return true
+	}
+}
+
+// mergePatch returns the indented RFC 7396 JSON merge patch, which turns
+// before into after. A merge patch can only describe the changes of an
+// object, for all other documents the merge patch is after itself.
+func mergePatch(before, after any, beforeJSON, afterJSON []byte) ([]byte, error) {
+	_, beforeIsObject := before.(map[string]any)
+	_, afterIsObject := after.(map[string]any)
+	if !beforeIsObject || !afterIsObject {
+		// evanphx.CreateMergePatch compares arrays element by element, which
+		// is not part of RFC 7396.
+		return indentJSON(bytes.TrimSpace(afterJSON))
+	}
+
+	data, err := evanphx.CreateMergePatch(beforeJSON, afterJSON)
+	if err != nil {
+		return nil, err
+	}
+	return indentJSON(data)
+}
+
+// marshalPatch returns the indented JSON representation of a patch returned
+// by one of the patch libraries.
+func marshalPatch(patch any) ([]byte, error) {
+	if p, ok := patch.([]byte); ok {
+		// Some libraries return an empty patch, if there are no changes.
+		if len(bytes.TrimSpace(p)) == 0 {
+			p = []byte("[]")
+		}
+		return indentJSON(p)
+	}
+	return marshalIndent(patch)
+}
+
+func marshalIndent(v any) ([]byte, error) {
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func indentJSON(data []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := json.Indent(&buf, data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// jsonDiffPatchDelta returns the delta between before and after in the
+// format used by jsondiffpatch
+// (https://github.com/benjamine/jsondiffpatch/blob/master/docs/deltas.md).
+// Array elements are compared by position, moves are not detected.
+func jsonDiffPatchDelta(before, after any) any {
+	delta, ok := jsonDiffPatchValueDelta(before, after)
+	if !ok {
+		return map[string]any{}
+	}
+	return delta
+}
+
+func jsonDiffPatchValueDelta(before, after any) (any, bool) {
+	switch bt := before.(type) {
+	case map[string]any:
+		at, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+
+		delta := map[string]any{}
+		for k, bv := range bt {
+			av, ok := at[k]
+			if !ok {
+				delta[k] = []any{bv, 0, 0}
+				continue
+			}
+			if d, changed := jsonDiffPatchValueDelta(bv, av); changed {
+				delta[k] = d
+			}
+		}
+		for k, av := range at {
+			if _, ok := bt[k]; !ok {
+				delta[k] = []any{av}
+			}
+		}
+		return delta, len(delta) > 0
+
+	case []any:
+		at, ok := after.([]any)
+		if !ok {
+			break
+		}
+
+		delta := map[string]any{}
+		for i := 0; i < max(len(bt), len(at)); i++ {
+			switch {
+			case i >= len(at):
+				delta["_"+strconv.Itoa(i)] = []any{bt[i], 0, 0}
+			case i >= len(bt):
+				delta[strconv.Itoa(i)] = []any{at[i]}
+			default:
+				if d, changed := jsonDiffPatchValueDelta(bt[i], at[i]); changed {
+					delta[strconv.Itoa(i)] = d
+				}
+			}
+		}
+		if len(delta) == 0 {
+			return nil, false
+		}
+		delta["_t"] = "a"
+		return delta, true
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil, false
+	}
+	return []any{before, after}, true
+}
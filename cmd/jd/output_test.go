@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	evanphx "github.com/evanphx/json-patch/v5"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestOutputPatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "replaced",
+			before: `{"a": 1, "b": [1]}`,
+			after:  `{"a": 2, "b": [1]}`,
+
+			want: `[
+  {
+    "op": "replace",
+    "path": "/a",
+    "value": 2
+  }
+]
+`,
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name:   "array element added",
+			before: `[1]`,
+			after:  `[1, {"a": null}]`,
+
+			want: `[
+  {
+    "op": "add",
+    "path": "/1",
+    "value": {
+      "a": null
+    }
+  }
+]
+`,
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name:   "equal",
+			before: `{"a": 1}`,
+			after:  `{"a": 1}`,
+
+			want: "[]\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"before.json": tc.before,
+				"after.json":  tc.after,
+			})
+
+			got, err := runJD(t, "-o", "patch", filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json"))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOutputMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+
+		want string
+	}{
+		{
+			name:   "objects",
+			before: `{"a": 1, "b": {"c": 1, "d": 1}, "e": [1, 2]}`,
+			after:  `{"a": 2, "b": {"c": 1}, "e": [2], "f": "g"}`,
+
+			want: `{"a": 2, "b": {"d": null}, "e": [2], "f": "g"}`,
+		},
+		{
+			name:   "nested array of objects",
+			before: `{"a": [{"b": 1, "c": 1}]}`,
+			after:  `{"a": [{"b": 2, "c": 1}]}`,
+
+			want: `{"a": [{"b": 2, "c": 1}]}`,
+		},
+		{
+			name:   "arrays of objects",
+			before: `[{"a": 1, "b": 1}]`,
+			after:  `[{"a": 2, "b": 1}]`,
+
+			want: `[{"a": 2, "b": 1}]`,
+		},
+		{
+			name:   "object to array",
+			before: `{"x": 1}`,
+			after:  `[1]`,
+
+			want: `[1]`,
+		},
+		{
+			name:   "array to object",
+			before: `[1]`,
+			after:  `{"x": 1}`,
+
+			want: `{"x": 1}`,
+		},
+		{
+			name:   "scalars",
+			before: `1`,
+			after:  `"a"`,
+
+			want: `"a"`,
+		},
+		{
+			name:   "equal",
+			before: `{"a": [1]}`,
+			after:  `{"a": [1]}`,
+
+			want: `{}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"before.json": tc.before,
+				"after.json":  tc.after,
+			})
+
+			got, err := runJD(t, "-o", "merge-patch", filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json"))
+			if err != nil && !errors.Is(err, errDocumentsDiffer) {
+				t.Fatalf("want no error or %v, got %v", errDocumentsDiffer, err)
+			}
+
+			require.Equal(t, decodeJSON(t, tc.want), decodeJSON(t, got))
+
+			// Applying the merge patch according to RFC 7396 results in after.
+			patched, err := evanphx.MergePatch([]byte(tc.before), []byte(got))
+			require.NoError(t, err)
+			require.Equal(t, decodeJSON(t, tc.after), decodeJSON(t, string(patched)))
+		})
+	}
+}
+
+func TestOutputJSONDiffPatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+
+		want string
+	}{
+		{
+			name:   "added",
+			before: `{"a": 1}`,
+			after:  `{"a": 1, "b": {"c": 2}}`,
+
+			want: `{"b": [{"c": 2}]}`,
+		},
+		{
+			name:   "deleted",
+			before: `{"a": 1, "b": 2}`,
+			after:  `{"a": 1}`,
+
+			want: `{"b": [2, 0, 0]}`,
+		},
+		{
+			name:   "replaced",
+			before: `{"a": 1, "b": {"c": "d"}}`,
+			after:  `{"a": "1", "b": {"c": "e"}}`,
+
+			want: `{"a": [1, "1"], "b": {"c": ["d", "e"]}}`,
+		},
+		{
+			name:   "type changed",
+			before: `{"a": {"b": 1}}`,
+			after:  `{"a": [1]}`,
+
+			want: `{"a": [{"b": 1}, [1]]}`,
+		},
+		{
+			name:   "array elements",
+			before: `{"a": [1, {"b": 1}, 3, 4]}`,
+			after:  `{"a": [1, {"b": 2}, 5]}`,
+
+			want: `{"a": {"_t": "a", "1": {"b": [1, 2]}, "2": [3, 5], "_3": [4, 0, 0]}}`,
+		},
+		{
+			name:   "array element added",
+			before: `[1]`,
+			after:  `[1, 2]`,
+
+			want: `{"_t": "a", "1": [2]}`,
+		},
+		{
+			name:   "equal",
+			before: `{"a": [1]}`,
+			after:  `{"a": [1]}`,
+
+			want: `{}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"before.json": tc.before,
+				"after.json":  tc.after,
+			})
+
+			got, err := runJD(t, "-o", "jsondiffpatch", filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json"))
+			if err != nil && !errors.Is(err, errDocumentsDiffer) {
+				t.Fatalf("want no error or %v, got %v", errDocumentsDiffer, err)
+			}
+
+			require.Equal(t, decodeJSON(t, tc.want), decodeJSON(t, got))
+		})
+	}
+}
+
+func decodeJSON(t *testing.T, s string) any {
+	t.Helper()
+
+	var v any
+	err := json.Unmarshal([]byte(s), &v)
+	require.NoError(t, err)
+	return v
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+const defaultPager = "less -R"
+
+// page writes the output to out. If the output does not fit on the screen,
+// it is piped through the pager, which is taken from the environment
+// variables JD_PAGER or PAGER, defaulting to "less -R".
+func (a App) page(out io.Writer, output []byte) error {
+	if a.noPager || !a.exceedsScreen(out, output) {
+		_, err := out.Write(output)
+		return err
+	}
+
+	pager := os.Getenv("JD_PAGER")
+	if pager == "" {
+		pager = os.Getenv("PAGER")
+	}
+	if pager == "" {
+		pager = defaultPager
+	}
+	if pager == "cat" {
+		_, err := out.Write(output)
+		return err
+	}
+
+	cmd := shellCommand(context.Background(), pager)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// exceedsScreen reports whether the output has more lines than the terminal
+// connected to out. If the size of the terminal is unknown, true is returned.
+func (a App) exceedsScreen(out io.Writer, output []byte) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return true
+	}
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return true
+	}
+	return bytes.Count(output, []byte("\n")) >= height
+}
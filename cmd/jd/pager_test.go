@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestPager(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"before.json": `{"a": 1}`,
+		"after.json":  `{"a": 2}`,
+	})
+	before := filepath.Join(dir, "before.json")
+	after := filepath.Join(dir, "after.json")
+
+	diff := `  {
+-   "a": 1
++   "a": 2
+  }
+`
+
+	tests := []struct {
+		name     string
+		args     []string
+		terminal bool
+		jdPager  string
+		pager    string
+		// shell is true, if the pager requires a POSIX shell.
+		shell bool
+
+		want string
+	}{
+		{
+			name:     "no terminal",
+			args:     []string{before, after},
+			terminal: false,
+			pager:    "exit 1",
+
+			want: diff,
+		},
+		{
+			name:     "no pager",
+			args:     []string{"--no-pager", before, after},
+			terminal: true,
+			pager:    "exit 1",
+
+			want: diff,
+		},
+		{
+			name:     "cat",
+			args:     []string{before, after},
+			terminal: true,
+			pager:    "cat",
+
+			want: diff,
+		},
+		{
+			name:     "pager",
+			args:     []string{before, after},
+			terminal: true,
+			pager:    "sed 's/^/pager: /'",
+			shell:    true,
+
+			want: "pager:   {\npager: -   \"a\": 1\npager: +   \"a\": 2\npager:   }\n",
+		},
+		{
+			name:     "JD_PAGER takes precedence over PAGER",
+			args:     []string{before, after},
+			terminal: true,
+			jdPager:  "sed 's/^/jd: /'",
+			pager:    "exit 1",
+			shell:    true,
+
+			want: "jd:   {\njd: -   \"a\": 1\njd: +   \"a\": 2\njd:   }\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.shell && runtime.GOOS == "windows" {
+				t.Skip("the pager requires a POSIX shell")
+			}
+
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("NO_COLOR", "1")
+			t.Setenv("JD_PAGER", tc.jdPager)
+			t.Setenv("PAGER", tc.pager)
+
+			buf := bytes.Buffer{}
+			err := run(append([]string{"jd"}, tc.args...), &buf, tc.terminal)
+			if !errors.Is(err, errDocumentsDiffer) {
+				t.Fatalf("want error %v, got %v", errDocumentsDiffer, err)
+			}
+
+			require.EqualStringWithTabwriter(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the pager requires a POSIX shell")
+	}
+
+	t.Setenv("JD_PAGER", "exit 3")
+
+	app := App{}
+	err := app.page(&bytes.Buffer{}, []byte("output\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	evanphx "github.com/evanphx/json-patch/v5"
+	"github.com/urfave/cli/v2"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+func (a *App) patchCommand() *cli.Command {
+	flags := &App{}
+	return &cli.Command{
+		Name:      "patch",
+		Usage:     "Show the effect of applying a JSON patch (RFC 6902) to a document.",
+		ArgsUsage: "original.json patch.json",
+		Before:    a.mergeSubcommandFlags(flags),
+		Action:    a.RunPatch,
+		Flags: append(flags.formatFlags(),
+			&cli.StringFlag{
+				Name:        "write",
+				Aliases:     []string{"w"},
+				Usage:       "write the patched document to the given file, use - for stdout",
+				Destination: &a.writePatched,
+			},
+		),
+	}
+}
+
+func (a *App) RunPatch(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		_ = cli.ShowSubcommandHelp(ctx)
+		return fmt.Errorf("missing arguments, usage: jd patch <original.json> <patch.json>")
+	}
+
+	originalName, patchName := ctx.Args().Get(0), ctx.Args().Get(1)
+	if originalName == stdinName && patchName == stdinName {
+		return fmt.Errorf("stdin can only be used for either original or patch")
+	}
+
+	err := a.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	app, err := a.forFile(originalName)
+	if err != nil {
+		return err
+	}
+	a = &app
+
+	originalData, err := readInput(ctx, originalName)
+	if err != nil {
+		return fmt.Errorf("failed to read original (%s): %w", originalName, err)
+	}
+	patchData, err := readInput(ctx, patchName)
+	if err != nil {
+		return fmt.Errorf("failed to read patch (%s): %w", patchName, err)
+	}
+
+	original, originalJSON, err := decodeInput(input{name: originalName, data: originalData}, a.inputFormat)
+	if err != nil {
+		return fmt.Errorf("failed to decode original (%s): %w", originalName, err)
+	}
+	_, patchJSON, err := decodeInput(input{name: patchName, data: patchData}, a.inputFormat)
+	if err != nil {
+		return fmt.Errorf("failed to decode patch (%s): %w", patchName, err)
+	}
+
+	diffPatchJSON, err := rewriteMoveCopy(originalJSON, patchJSON)
+	if err != nil {
+		return err
+	}
+
+	err = a.formatDiff(ctx.App.Writer, original, diffPatchJSON)
+	if err != nil && !errors.Is(err, errDocumentsDiffer) {
+		return err
+	}
+
+	if a.writePatched != "" {
+		writeErr := a.writePatchedDocument(ctx, originalJSON, patchJSON)
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+
+	return err
+}
+
+// writePatchedDocument applies the patch to the original document and writes
+// the result to the file given with --write.
+func (a App) writePatchedDocument(ctx *cli.Context, originalJSON []byte, patchJSON []byte) error {
+	patch, err := evanphx.DecodePatch(patchJSON)
+	if err != nil {
+		return fmt.Errorf("failed to decode patch: %w", err)
+	}
+
+	patched, err := patch.ApplyIndent(originalJSON, "  ")
+	if err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+	patched = append(patched, '\n')
+
+	if a.writePatched == stdinName {
+		_, err = ctx.App.Writer.Write(patched)
+		return err
+	}
+
+	err = os.WriteFile(a.writePatched, patched, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write patched document: %w", err)
+	}
+
+	return nil
+}
+
+// rewriteMoveCopy rewrites the move and copy operations of the patch, which
+// are not supported by the formatter, into the equivalent remove and add
+// operations. The moved or copied value is taken from the document, that
+// results from applying the preceding operations to the original document.
+func rewriteMoveCopy(originalJSON []byte, patchJSON []byte) ([]byte, error) {
+	var ops []map[string]json.RawMessage
+	err := json.Unmarshal(patchJSON, &ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode patch: %w", err)
+	}
+
+	rewritten := make([]map[string]json.RawMessage, 0, len(ops))
+	var changed bool
+	for i, op := range ops {
+		var operation string
+		_ = json.Unmarshal(op["op"], &operation)
+		if operation != "move" && operation != "copy" {
+			rewritten = append(rewritten, op)
+			continue
+		}
+
+		value, err := valueAfter(originalJSON, ops[:i], op["from"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s operation %d: %w", operation, i, err)
+		}
+
+		if operation == "move" {
+			rewritten = append(rewritten, map[string]json.RawMessage{
+				"op":   json.RawMessage(`"remove"`),
+				"path": op["from"],
+			})
+		}
+		rewritten = append(rewritten, map[string]json.RawMessage{
+			"op":    json.RawMessage(`"add"`),
+			"path":  op["path"],
+			"value": value,
+		})
+		changed = true
+	}
+
+	if !changed {
+		return patchJSON, nil
+	}
+	return json.Marshal(rewritten)
+}
+
+// valueAfter returns the value located at the JSON pointer from in the
+// document, that results from applying ops to the original document.
+func valueAfter(originalJSON []byte, ops []map[string]json.RawMessage, from json.RawMessage) (json.RawMessage, error) {
+	var path string
+	err := json.Unmarshal(from, &path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from: %w", err)
+	}
+
+	opsJSON, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := evanphx.DecodePatch(opsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode patch: %w", err)
+	}
+	docJSON, err := patch.Apply(originalJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	var doc any
+	err = json.Unmarshal(docJSON, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := getValue(doc, jsonpointer.NewPointerFromPath(path))
+	if !ok {
+		return nil, fmt.Errorf("no value at %q", path)
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "add",
+			patch: `[{"op": "add", "path": "/c", "value": true}]`,
+
+			want: `  {
+    "a": {
+      "x": 1
+    },
+    "b": [
+      1,
+      2
+    ],
++   "c": true
+  }
+`,
+		},
+		{
+			name:  "move",
+			patch: `[{"op": "move", "from": "/a/x", "path": "/y"}]`,
+
+			want: `  {
+    "a": {
+-     "x": 1
+    },
+    "b": [
+      1,
+      2
+    ],
++   "y": 1
+  }
+`,
+		},
+		{
+			name:  "move array element",
+			patch: `[{"op": "move", "from": "/b/1", "path": "/b/0"}]`,
+
+			want: `  {
+    "a": {
+      "x": 1
+    },
+    "b": [
++     2,
+      1,
+-     2
+    ]
+  }
+`,
+		},
+		{
+			name:  "copy",
+			patch: `[{"op": "copy", "from": "/a", "path": "/c"}]`,
+
+			want: `  {
+    "a": {
+      "x": 1
+    },
+    "b": [
+      1,
+      2
+    ],
++   "c": {
++     "x": 1
+    }
+  }
+`,
+		},
+		{
+			name:  "copy value of preceding operation",
+			patch: `[{"op": "replace", "path": "/a/x", "value": 3}, {"op": "copy", "from": "/a/x", "path": "/c"}]`,
+
+			want: `  {
+    "a": {
+-     "x": 1
++     "x": 3
+    },
+    "b": [
+      1,
+      2
+    ],
++   "c": 3
+  }
+`,
+		},
+		{
+			name:  "move from missing path",
+			patch: `[{"op": "move", "from": "/z", "path": "/y"}]`,
+
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"original.json": `{"a": {"x": 1}, "b": [1, 2]}`,
+				"patch.json":    tc.patch,
+			})
+
+			got, err := runJD(t, "patch", filepath.Join(dir, "original.json"), filepath.Join(dir, "patch.json"))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			if !errors.Is(err, errDocumentsDiffer) {
+				t.Fatalf("want error %v, got %v", errDocumentsDiffer, err)
+			}
+
+			require.EqualStringWithTabwriter(t, tc.want, got)
+		})
+	}
+}
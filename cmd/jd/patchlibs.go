@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// patchLibResult is the outcome of computing the patch between before and
+// after with one of the patch libraries.
+type patchLibResult struct {
+	lib string
+	ops int
+	// roundTrip is true, if applying the patch to before results in after.
+	roundTrip bool
+	err       error
+}
+
+// compareAllPatchLibs writes the output for each of the patch libraries to
+// w, followed by a comparison of the resulting patches.
+func (a App) compareAllPatchLibs(w io.Writer, before, after any, beforeJSON, afterJSON []byte) error {
+	results := make([]patchLibResult, 0, len(patchLibs))
+	for _, lib := range patchLibs {
+		result := patchLibResult{lib: lib}
+
+		// The comparison of JSON in JSON uses the configured patch library.
+		libApp := a
+		libApp.patchLib = lib
+
+		patch, err := computePatchSafe(lib, before, after, beforeJSON, afterJSON)
+		if err == nil {
+			result.ops, result.roundTrip, err = checkPatch(patch, beforeJSON, after)
+		}
+
+		buf := bytes.Buffer{}
+		if err == nil {
+			err = libApp.writeOutput(&buf, before, after, beforeJSON, afterJSON, patch)
+			if errors.Is(err, errDocumentsDiffer) {
+				err = nil
+			}
+		}
+		result.err = err
+
+		if !a.quiet {
+			fmt.Fprintf(w, "=== %s ===\n", lib)
+			if err != nil {
+				fmt.Fprintf(w, "Error: %v\n", err)
+			}
+			_, _ = buf.WriteTo(w)
+			fmt.Fprintln(w)
+		}
+
+		results = append(results, result)
+	}
+
+	if !a.quiet {
+		writePatchLibComparison(w, results)
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		return errDocumentsDiffer
+	}
+	return nil
+}
+
+// computePatchSafe computes the patch like computePatch, but returns an error
+// instead of panicking, if the patch library panics.
+func computePatchSafe(lib string, before, after any, beforeJSON, afterJSON []byte) (patch any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return computePatch(lib, before, after, beforeJSON, afterJSON)
+}
+
+// checkPatch returns the number of operations of the patch and whether
+// applying the patch to before results in after.
+func checkPatch(patch any, beforeJSON []byte, after any) (int, bool, error) {
+	data, err := patchJSON(patch)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var ops []json.RawMessage
+	err = json.Unmarshal(data, &ops)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to decode patch: %w", err)
+	}
+
+	return len(ops), verifyPatch(patch, beforeJSON, after) == nil, nil
+}
+
+func writePatchLibComparison(w io.Writer, results []patchLibResult) {
+	buf := bytes.Buffer{}
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Library\tOps\tRound-trip\tError")
+	for _, result := range results {
+		ops, roundTrip, errMsg := strconv.Itoa(result.ops), "no", ""
+		if result.roundTrip {
+			roundTrip = "yes"
+		}
+		if result.err != nil {
+			ops, roundTrip, errMsg = "-", "-", result.err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.lib, ops, roundTrip, errMsg)
+	}
+	_ = tw.Flush()
+
+	// Remove the padding of the empty error column.
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		_, _ = io.WriteString(w, strings.TrimRight(line, " ")+"\n")
+	}
+}
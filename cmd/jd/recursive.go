@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/urfave/cli/v2"
+)
+
+// runRecursive compares all the files in the directories beforeDir and
+// afterDir, which share the same relative path.
+func (a App) runRecursive(ctx *cli.Context, beforeDir, afterDir string) error {
+	if a.execBefore != "" || a.execAfter != "" {
+		return fmt.Errorf("--exec-before and --exec-after are not supported in recursive mode")
+	}
+
+	beforeFiles, err := a.listFiles(beforeDir)
+	if err != nil {
+		return fmt.Errorf("failed to list files in %s: %w", beforeDir, err)
+	}
+	afterFiles, err := a.listFiles(afterDir)
+	if err != nil {
+		return fmt.Errorf("failed to list files in %s: %w", afterDir, err)
+	}
+
+	files := make([]string, 0, len(beforeFiles)+len(afterFiles))
+	files = append(files, beforeFiles...)
+	files = append(files, afterFiles...)
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	var differ bool
+	var failed int
+	for _, file := range files {
+		inBefore := slices.Contains(beforeFiles, file)
+		inAfter := slices.Contains(afterFiles, file)
+		if !inBefore || !inAfter {
+			differ = true
+			dir := beforeDir
+			if inAfter {
+				dir = afterDir
+			}
+			if !a.quiet {
+				fmt.Fprintf(ctx.App.Writer, "Only in %s: %s\n", dir, file)
+			}
+			continue
+		}
+
+		beforeFile := filepath.Join(beforeDir, filepath.FromSlash(file))
+		afterFile := filepath.Join(afterDir, filepath.FromSlash(file))
+
+		app, err := a.forFile(file)
+		if err != nil {
+			return err
+		}
+
+		buf := bytes.Buffer{}
+		err = app.diffFiles(&buf, beforeFile, afterFile)
+		if errors.Is(err, errDocumentsDiffer) {
+			differ = true
+			if !a.quiet {
+				fmt.Fprintf(ctx.App.Writer, "--- %s\n+++ %s\n", beforeFile, afterFile)
+				_, _ = buf.WriteTo(ctx.App.Writer)
+			}
+			continue
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(ctx.App.ErrWriter, "Error: %v\n", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to compare %d file(s)", failed)
+	}
+	if differ {
+		return errDocumentsDiffer
+	}
+	return nil
+}
+
+// diffFiles writes the diff between the files beforeFile and afterFile to w.
+// Files with identical content are skipped.
+func (a App) diffFiles(w io.Writer, beforeFile, afterFile string) error {
+	beforeData, err := os.ReadFile(beforeFile)
+	if err != nil {
+		return err
+	}
+	afterData, err := os.ReadFile(afterFile)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(beforeData, afterData) {
+		return nil
+	}
+
+	return a.diff(w, input{name: beforeFile, data: beforeData}, input{name: afterFile, data: afterData})
+}
+
+// listFiles returns the slash separated paths, relative to dir, of all the
+// regular files in dir, which match the include and exclude patterns.
+func (a App) listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if len(a.include.Value()) > 0 && !matchAny(a.include.Value(), rel) {
+			return nil
+		}
+		if matchAny(a.exclude.Value(), rel) {
+			return nil
+		}
+
+		files = append(files, rel)
+		return nil
+	})
+
+	return files, err
+}
+
+// matchAny reports whether the relative path or its base name matches any of
+// the glob patterns.
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
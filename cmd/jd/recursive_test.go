@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestRecursive(t *testing.T) {
+	beforeDir := writeFiles(t, map[string]string{
+		"same.json":         `{"a": 1}`,
+		"same-invalid.json": `{`,
+		"changed.json":      `{"a": 1}`,
+		"only-before.json":  `{}`,
+		"sub/changed.json":  `[1]`,
+		"sub/skip.json":     `{"a": 1}`,
+	})
+	afterDir := writeFiles(t, map[string]string{
+		"same.json":         `{"a": 1}`,
+		"same-invalid.json": `{`,
+		"changed.json":      `{"a": 2}`,
+		"only-after.json":   `{}`,
+		"sub/changed.json":  `[2]`,
+		"sub/skip.json":     `{"a": 2}`,
+	})
+
+	changed := "--- " + filepath.Join(beforeDir, "changed.json") + "\n+++ " + filepath.Join(afterDir, "changed.json") + `
+  {
+-   "a": 1
++   "a": 2
+  }
+`
+	subChanged := "--- " + filepath.Join(beforeDir, "sub", "changed.json") + "\n+++ " + filepath.Join(afterDir, "sub", "changed.json") + `
+  [
+-   1
++   2
+  ]
+`
+	subSkip := "--- " + filepath.Join(beforeDir, "sub", "skip.json") + "\n+++ " + filepath.Join(afterDir, "sub", "skip.json") + `
+  {
+-   "a": 1
++   "a": 2
+  }
+`
+	onlyAfter := "Only in " + afterDir + ": only-after.json\n"
+	onlyBefore := "Only in " + beforeDir + ": only-before.json\n"
+
+	tests := []struct {
+		name string
+		args []string
+
+		want    string
+		wantErr error
+	}{
+		{
+			name: "all files",
+			args: []string{"-r", beforeDir, afterDir},
+
+			want:    changed + onlyAfter + onlyBefore + subChanged + subSkip,
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name: "include base name",
+			args: []string{"-r", "--include", "changed.json", beforeDir, afterDir},
+
+			want:    changed + subChanged,
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name: "include relative path",
+			args: []string{"-r", "--include", "sub/*", beforeDir, afterDir},
+
+			want:    subChanged + subSkip,
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name: "exclude base name",
+			args: []string{"-r", "--exclude", "only-*", "--exclude", "changed.json", beforeDir, afterDir},
+
+			want:    subSkip,
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name: "exclude relative path",
+			args: []string{"-r", "--exclude", "sub/skip.json", "--exclude", "only-*", beforeDir, afterDir},
+
+			want:    changed + subChanged,
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name: "identical files",
+			args: []string{"-r", "--include", "same*.json", beforeDir, afterDir},
+
+			want: "",
+		},
+		{
+			name: "quiet",
+			args: []string{"-r", "-q", beforeDir, afterDir},
+
+			want:    "",
+			wantErr: errDocumentsDiffer,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := runJD(t, tc.args...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+
+			require.EqualStringWithTabwriter(t, tc.want, got)
+		})
+	}
+}
+
+func TestRecursiveDecodeError(t *testing.T) {
+	beforeDir := writeFiles(t, map[string]string{
+		"a.json": `{"a": 1}`,
+		"b.json": `{"b": 1}`,
+	})
+	afterDir := writeFiles(t, map[string]string{
+		"a.json": `{"a": 1`,
+		"b.json": `{"b": 2}`,
+	})
+
+	got, err := runJD(t, "-r", beforeDir, afterDir)
+	require.Error(t, err)
+	if errors.Is(err, errDocumentsDiffer) {
+		t.Fatalf("want error other than %v, got %v", errDocumentsDiffer, err)
+	}
+	require.Equal(t, "failed to compare 1 file(s)", err.Error())
+
+	// The remaining files are still compared.
+	want := "--- " + filepath.Join(beforeDir, "b.json") + "\n+++ " + filepath.Join(afterDir, "b.json") + `
+  {
+-   "b": 1
++   "b": 2
+  }
+`
+	require.EqualStringWithTabwriter(t, want, got)
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		rel      string
+
+		want bool
+	}{
+		{patterns: nil, rel: "a.json", want: false},
+		{patterns: []string{"*.json"}, rel: "a.json", want: true},
+		{patterns: []string{"*.json"}, rel: "sub/a.json", want: true},
+		{patterns: []string{"*.yaml", "*.json"}, rel: "sub/a.json", want: true},
+		{patterns: []string{"sub/*.json"}, rel: "sub/a.json", want: true},
+		{patterns: []string{"sub/*.json"}, rel: "other/a.json", want: false},
+		{patterns: []string{"sub/*"}, rel: "sub/deep/a.json", want: false},
+		{patterns: []string{"[invalid"}, rel: "a.json", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.rel, func(t *testing.T) {
+			require.Equal(t, tc.want, matchAny(tc.patterns, tc.rel))
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/breml/jsondiffprinter"
+)
+
+// maxRequestSize limits the size of the request body of POST /diff.
+const maxRequestSize = 10 << 20
+
+//go:embed web/index.html
+var indexHTML []byte
+
+func (a *App) serveCommand() *cli.Command {
+	flags := &App{}
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "Start an HTTP server with a web UI and an endpoint (POST /diff) to compute diffs.",
+		Description: `POST /diff accepts a JSON object with the documents "before" and "after" or
+   "original" and "patch" (RFC 6902). The optional fields "format", "patchlib"
+   and "hideUnchanged" override the flags. The field "output" selects the
+   response, either "text", "html" or "json", by default it is derived from
+   the Accept header.`,
+		Before: a.mergeSubcommandFlags(flags),
+		Action: a.RunServe,
+		Flags: append(flags.formatFlags(),
+			flags.patchLibFlag(false),
+			flags.arrayKeysFlag(),
+			&cli.StringFlag{
+				Name:        "addr",
+				Usage:       "address to listen on",
+				Value:       ":8080",
+				DefaultText: ":8080",
+				Destination: &a.addr,
+			},
+		),
+	}
+}
+
+func (a *App) RunServe(ctx *cli.Context) error {
+	err := a.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = a.singlePatchLib()
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              a.addr,
+		Handler:           a.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt)
+	defer stop()
+
+	go func() {
+		<-serveCtx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	// The log is written to stderr, since stdout might be buffered for the
+	// pager.
+	fmt.Fprintf(ctx.App.ErrWriter, "Listening on %s\n", a.addr)
+	err = server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// handler returns the HTTP handler serving the web UI and the diff endpoint.
+func (a App) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(indexHTML)
+	})
+	mux.HandleFunc("POST /diff", a.handleDiff)
+	return mux
+}
+
+type diffRequest struct {
+	Before   json.RawMessage `json:"before"`
+	After    json.RawMessage `json:"after"`
+	Original json.RawMessage `json:"original"`
+	Patch    json.RawMessage `json:"patch"`
+
+	Format        string `json:"format"`
+	PatchLib      string `json:"patchlib"`
+	HideUnchanged *bool  `json:"hideUnchanged"`
+	Output        string `json:"output"`
+}
+
+type diffResponse struct {
+	Changed      bool            `json:"changed"`
+	Diff         string          `json:"diff"`
+	Patch        json.RawMessage `json:"patch"`
+	Stats        diffStats       `json:"stats"`
+	ChangedPaths []string        `json:"changedPaths"`
+}
+
+type diffStats struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Replaced  int `json:"replaced"`
+	Unchanged int `json:"unchanged"`
+}
+
+func (a App) handleDiff(w http.ResponseWriter, r *http.Request) {
+	var req diffRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	err := decoder.Decode(&req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	app, err := a.forRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	output := req.Output
+	if output == "" {
+		output = outputFromAccept(r.Header.Get("Accept"))
+	}
+
+	switch output {
+	case "html":
+		app.color = "always"
+	case "text", "json":
+		app.color = "never"
+	default:
+		http.Error(w, fmt.Sprintf("output %q is not allowed, supported values: text, html, json", output), http.StatusBadRequest)
+		return
+	}
+
+	before, patch, err := app.requestPatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	buf := bytes.Buffer{}
+	result, err := jsondiffprinter.FormatWithResult(before, patch, app.formatOptions(&buf)...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to format using format %q: %v", app.format, err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	switch output {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = buf.WriteTo(w)
+
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<pre class="jd-diff">` + ansiToHTML(buf.String()) + "</pre>\n"))
+
+	case "json":
+		patchJSON, err := marshalPatch(patch)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to marshal patch: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		resp := diffResponse{
+			Changed: result.Changed,
+			Diff:    buf.String(),
+			Patch:   patchJSON,
+			Stats: diffStats{
+				Added:     result.Stats.Added.Total(),
+				Removed:   result.Stats.Removed.Total(),
+				Replaced:  result.Stats.Replaced.Total(),
+				Unchanged: result.Stats.Unchanged.Total(),
+			},
+			ChangedPaths: result.ChangedPaths,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// forRequest returns a copy of the app with the options given in the request
+// applied.
+func (a App) forRequest(req diffRequest) (App, error) {
+	if req.Format != "" {
+		if !slices.Contains([]string{"diff", "terraform"}, strings.ToLower(req.Format)) {
+			return App{}, fmt.Errorf("format %q is not allowed, supported values: diff, terraform", req.Format)
+		}
+		a.format = req.Format
+	}
+	if req.PatchLib != "" {
+		if !isPatchLib(req.PatchLib) {
+			return App{}, fmt.Errorf("patchlib %q is not allowed, supported values: %s", req.PatchLib, strings.Join(patchLibs, ", "))
+		}
+		a.patchLib = req.PatchLib
+	}
+	if req.HideUnchanged != nil {
+		a.hideUnchanged = *req.HideUnchanged
+	}
+
+	err := a.singlePatchLib()
+	if err != nil {
+		return App{}, err
+	}
+	return a, nil
+}
+
+// requestPatch returns the original document and the patch from the request.
+// If the request contains before and after, the patch is computed with the
+// configured patch library.
+func (a App) requestPatch(req diffRequest) (any, any, error) {
+	switch {
+	case req.Original != nil && req.Patch != nil:
+		var original any
+		err := json.Unmarshal(req.Original, &original)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode original: %w", err)
+		}
+		return original, []byte(req.Patch), nil
+
+	case req.Before != nil && req.After != nil:
+		before, beforeJSON, err := decodeInput(input{data: req.Before}, "json")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode before: %w", err)
+		}
+		after, afterJSON, err := decodeInput(input{data: req.After}, "json")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode after: %w", err)
+		}
+
+		before, after, beforeJSON, afterJSON, err = a.alignArrays(before, after, beforeJSON, afterJSON)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		patch, err := computePatchSafe(a.patchLib, before, after, beforeJSON, afterJSON)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to calculate JSON patch using %q: %w", a.patchLib, err)
+		}
+		return before, patch, nil
+
+	default:
+		return nil, nil, fmt.Errorf(`request must contain either "before" and "after" or "original" and "patch"`)
+	}
+}
+
+// outputFromAccept returns the output derived from the Accept header of the
+// request.
+func outputFromAccept(accept string) string {
+	switch {
+	case strings.Contains(accept, "application/json"):
+		return "json"
+	case strings.Contains(accept, "text/html"):
+		return "html"
+	default:
+		return "text"
+	}
+}
+
+var ansiToHTMLReplacer = strings.NewReplacer(
+	"\033[31m", `<span class="jd-red">`,
+	"\033[32m", `<span class="jd-green">`,
+	"\033[33m", `<span class="jd-yellow">`,
+	"\033[90m", `<span class="jd-grey">`,
+	"\033[0m", `</span>`,
+)
+
+// ansiToHTML escapes the output of the formatter for HTML and converts the
+// ANSI color codes into span elements.
+func ansiToHTML(s string) string {
+	return ansiToHTMLReplacer.Replace(html.EscapeString(s))
+}
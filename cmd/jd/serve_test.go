@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		accept string
+		body   string
+
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantContains    []string
+	}{
+		{
+			name:   "index page",
+			method: http.MethodGet,
+			path:   "/",
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        string(indexHTML),
+		},
+		{
+			name:   "output text",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": 1, "b": 2}, "after": {"a": 1, "b": 3}, "output": "text"}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody: `  {
+    "a": 1,
+-   "b": 2
++   "b": 3
+  }
+`,
+		},
+		{
+			name:   "output html",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": "<b>"}, "after": {"a": "<i>"}, "output": "html"}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantContains: []string{
+				`<pre class="jd-diff">`,
+				`<span class="jd-red">-</span>`,
+				`<span class="jd-green">+</span>`,
+				`&#34;&lt;b&gt;&#34;`,
+				"</pre>\n",
+			},
+		},
+		{
+			name:   "output json",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": 1, "b": 2}, "after": {"a": 1, "b": 3, "c": 4}, "output": "json"}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantContains: []string{
+				`"changed":true`,
+				`"stats":{"added":1,"removed":0,"replaced":1,"unchanged":1}`,
+				`"changedPaths":["/b","/c"]`,
+			},
+		},
+		{
+			name:   "output overrides accept header",
+			method: http.MethodPost,
+			path:   "/diff",
+			accept: "application/json",
+			body:   `{"before": {"a": 1}, "after": {"a": 1}, "output": "text"}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody: `  {
+    "a": 1
+  }
+`,
+		},
+		{
+			name:   "accept json",
+			method: http.MethodPost,
+			path:   "/diff",
+			accept: "application/json",
+			body:   `{"before": {"a": 1}, "after": {"a": 1}}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantContains:    []string{`"changed":false`},
+		},
+		{
+			name:   "accept html",
+			method: http.MethodPost,
+			path:   "/diff",
+			accept: "text/html,application/xhtml+xml",
+			body:   `{"before": {"a": 1}, "after": {"a": 2}}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantContains:    []string{`<pre class="jd-diff">`},
+		},
+		{
+			name:   "accept other",
+			method: http.MethodPost,
+			path:   "/diff",
+			accept: "*/*",
+			body:   `{"before": {"a": 1}, "after": {"a": 1}}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:   "original and patch",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"original": {"a": 1}, "patch": [{"op": "add", "path": "/b", "value": 2}], "output": "text"}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody: `  {
+    "a": 1,
++   "b": 2
+  }
+`,
+		},
+		{
+			name:   "format terraform",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": 1}, "after": {"a": 2}, "format": "terraform", "output": "text"}`,
+
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody: `  {
+    ~ a = 1 -> 2
+  }
+`,
+		},
+		{
+			name:   "invalid body",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before":`,
+
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{"invalid request"},
+		},
+		{
+			name:   "missing documents",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": 1}}`,
+
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`request must contain either "before" and "after" or "original" and "patch"`},
+		},
+		{
+			name:   "unknown format",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": 1}, "after": {"a": 2}, "format": "yaml"}`,
+
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`format "yaml" is not allowed`},
+		},
+		{
+			name:   "unknown patchlib",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": 1}, "after": {"a": 2}, "patchlib": "unknown"}`,
+
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`patchlib "unknown" is not allowed`},
+		},
+		{
+			name:   "patchlib panics",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": {"b": 1}}, "after": {"a": [1]}, "patchlib": "cameront"}`,
+
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`failed to calculate JSON patch using "cameront": panic:`},
+		},
+		{
+			name:   "unknown output",
+			method: http.MethodPost,
+			path:   "/diff",
+			body:   `{"before": {"a": 1}, "after": {"a": 2}, "output": "pdf"}`,
+
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`output "pdf" is not allowed`},
+		},
+		{
+			name:   "method not allowed",
+			method: http.MethodGet,
+			path:   "/diff",
+
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := App{
+				format:   "diff",
+				patchLib: "mattbaird",
+				color:    "auto",
+			}
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			app.handler().ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			if tc.wantContentType != "" {
+				require.Equal(t, tc.wantContentType, rec.Header().Get("Content-Type"))
+			}
+			if tc.wantBody != "" {
+				require.EqualStringWithTabwriter(t, tc.wantBody, rec.Body.String())
+			}
+			for _, want := range tc.wantContains {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("response body does not contain %q:\n%s", want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerJSONResponse(t *testing.T) {
+	app := App{
+		format:   "diff",
+		patchLib: "mattbaird",
+		color:    "auto",
+	}
+
+	body := `{"before": {"a": 1}, "after": {"a": 2}}`
+	req := httptest.NewRequest(http.MethodPost, "/diff", strings.NewReader(body))
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+
+	app.handler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp diffResponse
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	require.NoError(t, err)
+
+	var patch []map[string]any
+	err = json.Unmarshal(resp.Patch, &patch)
+	require.NoError(t, err)
+
+	require.Equal(t, true, resp.Changed)
+	require.Equal(t, []map[string]any{{"op": "replace", "path": "/a", "value": 2.0}}, patch)
+	require.Equal(t, []string{"/a"}, resp.ChangedPaths)
+	require.Equal(t, diffStats{Replaced: 1}, resp.Stats)
+	require.EqualStringWithTabwriter(t, `  {
+-   "a": 1
++   "a": 2
+  }
+`, resp.Diff)
+}
+
+func TestForRequestPatchLibAll(t *testing.T) {
+	app := App{format: "diff", patchLib: patchLibAll, color: "auto"}
+
+	_, err := app.forRequest(diffRequest{})
+	require.Error(t, err)
+
+	got, err := app.forRequest(diffRequest{PatchLib: "wI2L"})
+	require.NoError(t, err)
+	require.Equal(t, "wI2L", got.patchLib)
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/breml/jsondiffprinter"
+	"github.com/breml/jsondiffprinter/internal/jsonpatch"
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+const (
+	sensitiveValue = "(sensitive value)"
+
+	forcesReplacementNote = " # forces replacement"
+
+	// sensitivePlaceholder and changedSensitivePlaceholder are used as placeholders for
+	// sensitive values. If a sensitive value has changed, the placeholder in
+	// the after document differs from the one in the before document.
+	sensitivePlaceholder        = "\x00" + sensitiveValue
+	changedSensitivePlaceholder = "\x00" + sensitiveValue + " (changed)"
+)
+
+// tfPlan is the part of the JSON representation of a Terraform plan
+// (terraform show -json), which is relevant for the diff.
+type tfPlan struct {
+	ResourceChanges []tfResourceChange `json:"resource_changes"`
+}
+
+type tfResourceChange struct {
+	Address string   `json:"address"`
+	Mode    string   `json:"mode"`
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Change  tfChange `json:"change"`
+}
+
+type tfChange struct {
+	Actions         []string `json:"actions"`
+	Before          any      `json:"before"`
+	After           any      `json:"after"`
+	AfterUnknown    any      `json:"after_unknown"`
+	BeforeSensitive any      `json:"before_sensitive"`
+	AfterSensitive  any      `json:"after_sensitive"`
+	ReplacePaths    [][]any  `json:"replace_paths"`
+}
+
+func (a *App) tfplanCommand() *cli.Command {
+	flags := &App{}
+	return &cli.Command{
+		Name:      "tfplan",
+		Usage:     "Show the resource changes of a Terraform plan in JSON format (terraform show -json).",
+		ArgsUsage: "plan.json",
+		Before:    a.mergeSubcommandFlags(flags),
+		Action:    a.RunTFPlan,
+		Flags:     append(flags.formatFlags(), flags.patchLibFlag(false)),
+	}
+}
+
+func (a *App) RunTFPlan(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		_ = cli.ShowSubcommandHelp(ctx)
+		return fmt.Errorf("missing arguments, usage: jd tfplan <plan.json>")
+	}
+
+	err := a.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = a.singlePatchLib()
+	if err != nil {
+		return err
+	}
+
+	name := ctx.Args().Get(0)
+	data, err := readInput(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to read plan (%s): %w", name, err)
+	}
+
+	var plan tfPlan
+	err = json.Unmarshal(data, &plan)
+	if err != nil {
+		return fmt.Errorf("failed to decode plan (%s): %w", name, err)
+	}
+
+	w := ctx.App.Writer
+	if a.quiet {
+		w = io.Discard
+	}
+
+	// The plan is always rendered in the style of Terraform.
+	a.format = "terraform"
+
+	var add, change, destroy int
+	for _, rc := range plan.ResourceChanges {
+		action := jsondiffprinter.Action(strings.Join(rc.Change.Actions, ","))
+		switch action {
+		case jsondiffprinter.ActionCreate:
+			add++
+		case jsondiffprinter.ActionDelete:
+			destroy++
+		case jsondiffprinter.ActionUpdate:
+			change++
+		case jsondiffprinter.ActionDeleteThenCreate, jsondiffprinter.ActionCreateThenDelete:
+			add++
+			destroy++
+		case jsondiffprinter.ActionRead:
+			// Data sources read during apply are shown, but not counted.
+		default:
+			continue
+		}
+
+		err = a.formatResourceChange(w, rc, action)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", rc.Address, err)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if add+change+destroy == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Plan: %d to add, %d to change, %d to destroy.\n", add, change, destroy)
+	return errDocumentsDiffer
+}
+
+// formatResourceChange writes the diff of the resource change to w, enclosed
+// in a resource block. Unknown and sensitive values are rendered as
+// "(known after apply)" and "(sensitive value)", attributes forcing the
+// replacement of the resource are annotated with "# forces replacement".
+func (a App) formatResourceChange(w io.Writer, rc tfResourceChange, action jsondiffprinter.Action) error {
+	change := rc.Change
+	before, after := change.Before, change.After
+
+	sensitivePaths := append(tfMaskedPaths(change.BeforeSensitive), tfMaskedPaths(change.AfterSensitive)...)
+	for _, path := range sensitivePaths {
+		beforeValue, inBefore := getValue(before, path)
+		afterValue, inAfter := getValue(after, path)
+		if inBefore {
+			before = setValue(before, path, sensitivePlaceholder)
+		}
+		if inAfter {
+			placeholder := sensitivePlaceholder
+			if inBefore && !reflect.DeepEqual(beforeValue, afterValue) {
+				placeholder = changedSensitivePlaceholder
+			}
+			after = setValue(after, path, placeholder)
+		}
+	}
+
+	// The unknown values need to exist in after in order to be rendered by
+	// the formatter.
+	unknownPaths := tfMaskedPaths(change.AfterUnknown)
+	unknownPatterns := make([]string, 0, len(unknownPaths))
+	for _, path := range unknownPaths {
+		if _, ok := getValue(after, path); !ok {
+			after = setValue(after, path, nil)
+		}
+		unknownPatterns = append(unknownPatterns, path.String())
+	}
+
+	var patch any
+	switch {
+	case before == nil:
+		patch = []map[string]any{{"op": "add", "path": "", "value": after}}
+	case after == nil:
+		patch = []map[string]any{{"op": "remove", "path": ""}}
+	default:
+		beforeJSON, err := json.Marshal(before)
+		if err != nil {
+			return err
+		}
+		afterJSON, err := json.Marshal(after)
+		if err != nil {
+			return err
+		}
+		patch, err = computePatchSafe(a.patchLib, before, after, beforeJSON, afterJSON)
+		if err != nil {
+			return fmt.Errorf("failed to calculate JSON patch using %q: %w", a.patchLib, err)
+		}
+	}
+
+	options := a.formatOptions(w)
+	blockType := "resource"
+	if rc.Mode == "data" {
+		blockType = "data"
+	}
+	options = append(options,
+		jsondiffprinter.WithBlock(jsondiffprinter.Block{
+			Address: rc.Address,
+			Type:    blockType,
+			Labels:  []string{rc.Type, rc.Name},
+			Action:  action,
+		}),
+		jsondiffprinter.WithUnknownPaths(unknownPatterns...),
+		jsondiffprinter.WithValueRenderer("/**", func(v any) (string, bool) {
+			switch v {
+			case sensitivePlaceholder, changedSensitivePlaceholder:
+				return sensitiveValue, true
+			default:
+				return "", false
+			}
+		}),
+	)
+
+	replacePaths := make([]jsonpointer.Pointer, 0, len(change.ReplacePaths))
+	for _, path := range change.ReplacePaths {
+		replacePaths = append(replacePaths, tfPathPointer(path))
+	}
+	options = append(options, jsondiffprinter.WithPatchSeriesPostProcess(func(diff jsondiffprinter.Patch) jsondiffprinter.Patch {
+		return annotateReplacePaths(diff, replacePaths)
+	}))
+
+	return jsondiffprinter.Format(before, patch, options...)
+}
+
+// annotateReplacePaths adds the note "# forces replacement" to the operations
+// at the given paths.
+func annotateReplacePaths(diff jsondiffprinter.Patch, paths []jsonpointer.Pointer) jsondiffprinter.Patch {
+	for i := range diff {
+		for _, path := range paths {
+			if !diff[i].Path.Equals(path) {
+				continue
+			}
+			if diff[i].Metadata == nil {
+				diff[i].Metadata = make(map[string]string)
+			}
+			diff[i].Metadata["note"] = forcesReplacementNote
+			if diff[i].Operation == jsonpatch.OperationTest {
+				diff[i].Metadata["operationOverride"] = string(jsonpatch.OperationReplace)
+			}
+		}
+	}
+	return diff
+}
+
+// tfMaskedPaths returns the paths of the values marked with true in a mask as
+// used by Terraform for after_unknown, before_sensitive and after_sensitive.
+func tfMaskedPaths(mask any) []jsonpointer.Pointer {
+	var paths []jsonpointer.Pointer
+
+	var walk func(v any, path jsonpointer.Pointer)
+	walk = func(v any, path jsonpointer.Pointer) {
+		switch vt := v.(type) {
+		case bool:
+			if vt {
+				paths = append(paths, path)
+			}
+		case map[string]any:
+			for k, v := range vt {
+				walk(v, path.AppendKey(k))
+			}
+		case []any:
+			for i, v := range vt {
+				walk(v, path.AppendIndex(i))
+			}
+		}
+	}
+	walk(mask, jsonpointer.NewPointer())
+
+	return paths
+}
+
+// tfPathPointer converts a path as used by Terraform for replace_paths, a list
+// of attribute names and indices, into a JSON pointer.
+func tfPathPointer(path []any) jsonpointer.Pointer {
+	pointer := jsonpointer.NewPointer()
+	for _, step := range path {
+		switch st := step.(type) {
+		case string:
+			pointer = pointer.AppendKey(st)
+		case float64:
+			pointer = pointer.AppendIndex(int(st))
+		default:
+			pointer = pointer.AppendKey(fmt.Sprint(st))
+		}
+	}
+	return pointer
+}
+
+// getValue returns the value at path in doc.
+func getValue(doc any, path jsonpointer.Pointer) (any, bool) {
+	for _, token := range path {
+		switch dt := doc.(type) {
+		case map[string]any:
+			v, ok := dt[token]
+			if !ok {
+				return nil, false
+			}
+			doc = v
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(dt) {
+				return nil, false
+			}
+			doc = dt[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// setValue sets the value at path in doc and returns the modified document.
+// Missing objects along the path are created.
+func setValue(doc any, path jsonpointer.Pointer, value any) any {
+	if len(path) == 0 {
+		return value
+	}
+
+	switch dt := doc.(type) {
+	case map[string]any:
+		dt[path[0]] = setValue(dt[path[0]], path[1:], value)
+		return dt
+	case []any:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(dt) {
+			return doc
+		}
+		dt[i] = setValue(dt[i], path[1:], value)
+		return dt
+	case nil:
+		return map[string]any{path[0]: setValue(nil, path[1:], value)}
+	default:
+		return doc
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/breml/jsondiffprinter"
+	"github.com/breml/jsondiffprinter/internal/jsonpatch"
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestTFPlan(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("testdata", "tfplan", "plan.txt"))
+	require.NoError(t, err)
+
+	got, err := runJD(t, "tfplan", filepath.Join("testdata", "tfplan", "plan.json"))
+	if !errors.Is(err, errDocumentsDiffer) {
+		t.Fatalf("want error %v, got %v", errDocumentsDiffer, err)
+	}
+
+	require.EqualStringWithTabwriter(t, string(want), got)
+}
+
+func TestTFPlanSummary(t *testing.T) {
+	resourceChange := func(actions string) string {
+		return `{"address": "null_resource.a", "mode": "managed", "type": "null_resource", "name": "a", "change": {"actions": ` + actions + `, "before": {"a": 1}, "after": {"a": 2}}}`
+	}
+
+	tests := []struct {
+		name            string
+		resourceChanges string
+
+		want    string
+		wantErr error
+	}{
+		{
+			name:            "no resource changes",
+			resourceChanges: ``,
+
+			want: "No changes.\n",
+		},
+		{
+			name:            "no-op",
+			resourceChanges: resourceChange(`["no-op"]`),
+
+			want: "No changes.\n",
+		},
+		{
+			name:            "update",
+			resourceChanges: resourceChange(`["update"]`),
+
+			want:    "Plan: 0 to add, 1 to change, 0 to destroy.\n",
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name:            "create and update",
+			resourceChanges: resourceChange(`["create"]`) + "," + resourceChange(`["update"]`),
+
+			want:    "Plan: 1 to add, 1 to change, 0 to destroy.\n",
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name:            "delete",
+			resourceChanges: resourceChange(`["delete"]`),
+
+			want:    "Plan: 0 to add, 0 to change, 1 to destroy.\n",
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name:            "create before destroy",
+			resourceChanges: resourceChange(`["create", "delete"]`),
+
+			want:    "Plan: 1 to add, 0 to change, 1 to destroy.\n",
+			wantErr: errDocumentsDiffer,
+		},
+		{
+			name:            "read is not counted",
+			resourceChanges: resourceChange(`["read"]`),
+
+			want: "No changes.\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"plan.json": `{"resource_changes": [` + tc.resourceChanges + `]}`,
+			})
+
+			got, err := runJD(t, "tfplan", filepath.Join(dir, "plan.json"))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+
+			if !strings.HasSuffix(got, tc.want) {
+				t.Errorf("want output ending with %q, got:\n%s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTFMaskedPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		mask any
+
+		want []string
+	}{
+		{
+			name: "nil",
+			mask: nil,
+		},
+		{
+			name: "false",
+			mask: false,
+		},
+		{
+			name: "root",
+			mask: true,
+
+			want: []string{""},
+		},
+		{
+			name: "nested",
+			mask: map[string]any{
+				"a": true,
+				"b": false,
+				"c": map[string]any{"d": true, "e": map[string]any{}},
+				"f": []any{false, true, map[string]any{"g": true}},
+			},
+
+			want: []string{"/a", "/c/d", "/f/1", "/f/2/g"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, path := range tfMaskedPaths(tc.mask) {
+				got = append(got, path.String())
+			}
+			// The order of the keys of a map is random.
+			slices.Sort(got)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTFPathPointer(t *testing.T) {
+	require.Equal(t, "/network/0/subnet_id", tfPathPointer([]any{"network", 0.0, "subnet_id"}).String())
+	require.Equal(t, "/a~1b/true", tfPathPointer([]any{"a/b", true}).String())
+	require.Equal(t, "", tfPathPointer(nil).String())
+}
+
+func TestGetValue(t *testing.T) {
+	doc := map[string]any{
+		"a": map[string]any{"b": []any{1.0, map[string]any{"c": "d"}}},
+		"e": nil,
+	}
+
+	tests := []struct {
+		path string
+
+		want   any
+		wantOK bool
+	}{
+		{path: "", want: doc, wantOK: true},
+		{path: "/a/b/0", want: 1.0, wantOK: true},
+		{path: "/a/b/1/c", want: "d", wantOK: true},
+		{path: "/e", want: nil, wantOK: true},
+		{path: "/x"},
+		{path: "/a/b/2"},
+		{path: "/a/b/-1"},
+		{path: "/a/b/x"},
+		{path: "/a/b/0/c"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			got, ok := getValue(doc, jsonpointer.NewPointerFromPath(tc.path))
+
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   any
+		path  string
+		value any
+
+		want any
+	}{
+		{
+			name:  "root",
+			doc:   map[string]any{"a": 1.0},
+			path:  "",
+			value: "x",
+
+			want: "x",
+		},
+		{
+			name:  "existing key",
+			doc:   map[string]any{"a": 1.0},
+			path:  "/a",
+			value: "x",
+
+			want: map[string]any{"a": "x"},
+		},
+		{
+			name:  "missing objects are created",
+			doc:   map[string]any{},
+			path:  "/a/b",
+			value: nil,
+
+			want: map[string]any{"a": map[string]any{"b": nil}},
+		},
+		{
+			name:  "nil document",
+			doc:   nil,
+			path:  "/a",
+			value: "x",
+
+			want: map[string]any{"a": "x"},
+		},
+		{
+			name:  "array element",
+			doc:   []any{1.0, map[string]any{"a": 1.0}},
+			path:  "/1/a",
+			value: sensitivePlaceholder,
+
+			want: []any{1.0, map[string]any{"a": sensitivePlaceholder}},
+		},
+		{
+			name:  "array index out of range",
+			doc:   []any{1.0},
+			path:  "/1",
+			value: "x",
+
+			want: []any{1.0},
+		},
+		{
+			name:  "scalar",
+			doc:   map[string]any{"a": 1.0},
+			path:  "/a/b",
+			value: "x",
+
+			want: map[string]any{"a": 1.0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := setValue(tc.doc, jsonpointer.NewPointerFromPath(tc.path), tc.value)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSensitivePlaceholders(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+
+		want string
+	}{
+		{
+			name:   "unchanged",
+			before: `{"id": "1", "password": "old"}`,
+			after:  `{"id": "2", "password": "old"}`,
+
+			want: `        password = (sensitive value)`,
+		},
+		{
+			name:   "changed",
+			before: `{"id": "1", "password": "old"}`,
+			after:  `{"id": "2", "password": "new"}`,
+
+			want: `      ~ password = (sensitive value) -> (sensitive value)`,
+		},
+		{
+			name:   "added",
+			before: `{"id": "1"}`,
+			after:  `{"id": "2", "password": "new"}`,
+
+			want: `      + password = (sensitive value)`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"plan.json": `{"resource_changes": [{
+					"address": "null_resource.a", "mode": "managed", "type": "null_resource", "name": "a",
+					"change": {
+						"actions": ["update"],
+						"before": ` + tc.before + `,
+						"after": ` + tc.after + `,
+						"before_sensitive": {"password": true},
+						"after_sensitive": {"password": true}
+					}
+				}]}`,
+			})
+
+			got, _ := runJD(t, "tfplan", filepath.Join(dir, "plan.json"))
+
+			want := `  # null_resource.a will be updated in-place
+  ~ resource "null_resource" "a" {
+      ~ id = "1" -> "2"
+` + tc.want + `
+    }
+
+Plan: 0 to add, 1 to change, 0 to destroy.
+`
+			require.EqualStringWithTabwriter(t, want, got)
+		})
+	}
+}
+
+func TestAnnotateReplacePaths(t *testing.T) {
+	diff := jsondiffprinter.Patch{
+		{Operation: jsonpatch.OperationTest, Path: jsonpointer.NewPointerFromPath("")},
+		{Operation: jsonpatch.OperationReplace, Path: jsonpointer.NewPointerFromPath("/ami")},
+		{Operation: jsonpatch.OperationTest, Path: jsonpointer.NewPointerFromPath("/network"), Metadata: map[string]string{"key": "value"}},
+		{Operation: jsonpatch.OperationReplace, Path: jsonpointer.NewPointerFromPath("/network/0")},
+		{Operation: jsonpatch.OperationTest, Path: jsonpointer.NewPointerFromPath("/name")},
+	}
+
+	got := annotateReplacePaths(diff, []jsonpointer.Pointer{
+		jsonpointer.NewPointerFromPath("/ami"),
+		jsonpointer.NewPointerFromPath("/network"),
+		jsonpointer.NewPointerFromPath("/missing"),
+	})
+
+	require.Equal(t, []map[string]string{
+		nil,
+		{"note": forcesReplacementNote},
+		{"key": "value", "note": forcesReplacementNote, "operationOverride": "replace"},
+		nil,
+		nil,
+	}, []map[string]string{
+		got[0].Metadata,
+		got[1].Metadata,
+		got[2].Metadata,
+		got[3].Metadata,
+		got[4].Metadata,
+	})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	evanphx "github.com/evanphx/json-patch/v5"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+// verifyPatch applies the patch to before and checks, that the result is
+// equal to after. If not, the error contains the first pointer, at which the
+// patched document differs from after.
+func verifyPatch(patch any, beforeJSON []byte, after any) error {
+	data, err := patchJSON(patch)
+	if err != nil {
+		return err
+	}
+
+	decoded, err := evanphx.DecodePatch(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode patch: %w", err)
+	}
+	patched, err := decoded.Apply(beforeJSON)
+	if err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	var patchedDoc any
+	err = json.Unmarshal(patched, &patchedDoc)
+	if err != nil {
+		return fmt.Errorf("failed to decode patched document: %w", err)
+	}
+
+	path, got, want, differ := firstDifference(patchedDoc, after, jsonpointer.NewPointer())
+	if differ {
+		return fmt.Errorf("applying the patch does not result in after, at %q: got %s, want %s", path.String(), describeValue(got), describeValue(want))
+	}
+
+	return nil
+}
+
+// patchJSON returns the JSON representation of a patch returned by one of the
+// patch libraries.
+func patchJSON(patch any) ([]byte, error) {
+	data, ok := patch.([]byte)
+	if !ok {
+		var err error
+		data, err = json.Marshal(patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal patch: %w", err)
+		}
+	}
+
+	// Some libraries return an empty patch, if there are no changes.
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("[]")
+	}
+
+	return data, nil
+}
+
+// firstDifference returns the first pointer (in the order of the sorted object
+// keys and the array indices), at which a and b differ, together with the
+// values at this pointer. Values, which do not exist, are returned as
+// missingValue.
+func firstDifference(a, b any, path jsonpointer.Pointer) (jsonpointer.Pointer, any, any, bool) {
+	switch at := a.(type) {
+	case map[string]any:
+		bt, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(at)+len(bt))
+		for k := range at {
+			keys = append(keys, k)
+		}
+		for k := range bt {
+			if _, ok := at[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			av, aok := at[k]
+			bv, bok := bt[k]
+			if !aok {
+				return path.AppendKey(k), missingValue{}, bv, true
+			}
+			if !bok {
+				return path.AppendKey(k), av, missingValue{}, true
+			}
+			if p, ad, bd, differ := firstDifference(av, bv, path.AppendKey(k)); differ {
+				return p, ad, bd, true
+			}
+		}
+		return nil, nil, nil, false
+
+	case []any:
+		bt, ok := b.([]any)
+		if !ok {
+			break
+		}
+
+		for i := 0; i < max(len(at), len(bt)); i++ {
+			switch {
+			case i >= len(at):
+				return path.AppendIndex(i), missingValue{}, bt[i], true
+			case i >= len(bt):
+				return path.AppendIndex(i), at[i], missingValue{}, true
+			}
+			if p, ad, bd, differ := firstDifference(at[i], bt[i], path.AppendIndex(i)); differ {
+				return p, ad, bd, true
+			}
+		}
+		return nil, nil, nil, false
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil, nil, nil, false
+	}
+	return path, a, b, true
+}
+
+// missingValue represents a value, which does not exist in the document.
+type missingValue struct{}
+
+func describeValue(v any) string {
+	if _, ok := v.(missingValue); ok {
+		return "no value"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		patchLib string
+		before   string
+		after    string
+
+		wantErr string
+	}{
+		{
+			name:     "passes",
+			patchLib: "mattbaird",
+			before:   `{"a": 1, "b": [1], "c": {"d": true}}`,
+			after:    `{"a": 2, "b": [1, 2], "c": {}}`,
+		},
+		{
+			name:     "fails",
+			patchLib: "cameront",
+			before:   `{"a": [1, 2, 3]}`,
+			after:    `{"a": [3, 1]}`,
+
+			wantErr: `verification of the JSON patch calculated using "cameront" failed: applying the patch does not result in after, at "/a/0": got 1, want 3`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"before.json": tc.before,
+				"after.json":  tc.after,
+			})
+
+			got, err := runJD(t, "--verify", "-p", tc.patchLib, filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json"))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				require.Equal(t, tc.wantErr, err.Error())
+				require.Equal(t, "", got)
+				return
+			}
+			if !errors.Is(err, errDocumentsDiffer) {
+				t.Fatalf("want error %v, got %v", errDocumentsDiffer, err)
+			}
+		})
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+
+		wantPath string
+		wantA    any
+		wantB    any
+		wantDiff bool
+	}{
+		{
+			name: "equal",
+			a:    `{"a": [1, {"b": null}], "c": "d"}`,
+			b:    `{"c": "d", "a": [1, {"b": null}]}`,
+		},
+		{
+			name: "missing key",
+			a:    `{"a": 1, "c": 1}`,
+			b:    `{"a": 1, "b": 2, "c": 1}`,
+
+			wantPath: "/b",
+			wantA:    missingValue{},
+			wantB:    2.0,
+			wantDiff: true,
+		},
+		{
+			name: "extra key",
+			a:    `{"a": {"b": 1}}`,
+			b:    `{"a": {}}`,
+
+			wantPath: "/a/b",
+			wantA:    1.0,
+			wantB:    missingValue{},
+			wantDiff: true,
+		},
+		{
+			name: "first difference in order of the sorted keys",
+			a:    `{"b": 1, "a": 1}`,
+			b:    `{"b": 2, "a": 2}`,
+
+			wantPath: "/a",
+			wantA:    1.0,
+			wantB:    2.0,
+			wantDiff: true,
+		},
+		{
+			name: "extra array elements",
+			a:    `[1, 2, 3]`,
+			b:    `[1]`,
+
+			wantPath: "/1",
+			wantA:    2.0,
+			wantB:    missingValue{},
+			wantDiff: true,
+		},
+		{
+			name: "missing array elements",
+			a:    `{"a": []}`,
+			b:    `{"a": ["x"]}`,
+
+			wantPath: "/a/0",
+			wantA:    missingValue{},
+			wantB:    "x",
+			wantDiff: true,
+		},
+		{
+			name: "type mismatch",
+			a:    `{"a": {"b": 1}}`,
+			b:    `{"a": [1]}`,
+
+			wantPath: "/a",
+			wantA:    map[string]any{"b": 1.0},
+			wantB:    []any{1.0},
+			wantDiff: true,
+		},
+		{
+			name: "type mismatch of scalars",
+			a:    `[1]`,
+			b:    `["1"]`,
+
+			wantPath: "/0",
+			wantA:    1.0,
+			wantB:    "1",
+			wantDiff: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path, gotA, gotB, differ := firstDifference(decodeJSON(t, tc.a), decodeJSON(t, tc.b), jsonpointer.NewPointer())
+
+			require.Equal(t, tc.wantDiff, differ)
+			require.Equal(t, tc.wantPath, path.String())
+			require.Equal(t, tc.wantA, gotA)
+			require.Equal(t, tc.wantB, gotB)
+		})
+	}
+}
+
+func TestDescribeValue(t *testing.T) {
+	require.Equal(t, "no value", describeValue(missingValue{}))
+	require.Equal(t, `{"a":[1,"b"]}`, describeValue(map[string]any{"a": []any{1.0, "b"}}))
+	require.Equal(t, "null", describeValue(nil))
+}
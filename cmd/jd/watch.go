@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// watchDebounce is the time to wait for further changes before the diff is
+	// rendered, since editors often write a file in multiple steps.
+	watchDebounce = 100 * time.Millisecond
+
+	// watchPollInterval is the interval in which the files are checked for
+	// changes, if file system notifications are not available.
+	watchPollInterval = 500 * time.Millisecond
+
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// runWatch renders the diff and re-renders it every time one of the watched
+// files changes, until the command is interrupted. The output is written
+// directly to stdout, bypassing the pager.
+func (a App) runWatch(ctx *cli.Context) error {
+	if a.recursive {
+		return fmt.Errorf("--watch is not supported in recursive mode")
+	}
+
+	var files []string
+	for _, name := range ctx.Args().Slice() {
+		if name == stdinName {
+			return fmt.Errorf("--watch is not supported for stdin")
+		}
+		files = append(files, name)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("--watch requires at least one file")
+	}
+
+	watchCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt)
+	defer stop()
+
+	changes := watchFiles(watchCtx, files)
+	for {
+		a.render(ctx, files)
+
+		select {
+		case <-watchCtx.Done():
+			return nil
+		case <-changes:
+		}
+	}
+}
+
+// render clears the screen and writes the diff to stdout. Errors are printed
+// instead of returned, such that watching continues, e.g. if a file is
+// temporarily invalid while it is edited.
+func (a App) render(ctx *cli.Context, files []string) {
+	buf := bytes.Buffer{}
+	if a.terminal {
+		buf.WriteString(clearScreen)
+	}
+	fmt.Fprintf(&buf, "Watching %s, last update: %s (press Ctrl+C to exit)\n\n", joinNames(files), time.Now().Format(time.TimeOnly))
+
+	err := func() error {
+		beforeInput, afterInput, err := a.readInputs(ctx)
+		if err != nil {
+			return err
+		}
+		app, err := a.forFile(afterInput.name)
+		if err != nil {
+			return err
+		}
+		return app.diff(&buf, beforeInput, afterInput)
+	}()
+	if err != nil && !errors.Is(err, errDocumentsDiffer) {
+		fmt.Fprintf(&buf, "Error: %v\n", err)
+	}
+
+	_, _ = io.Copy(a.stdout, &buf)
+}
+
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return names[0] + " and " + names[1]
+}
+
+// watchFiles returns a channel, which receives a value, after one of the
+// files has changed and no further change happened for watchDebounce. File
+// system notifications are used if available, otherwise the files are
+// polled.
+func watchFiles(ctx context.Context, files []string) <-chan struct{} {
+	events, err := notifyEvents(ctx, files)
+	if err != nil {
+		events = pollEvents(ctx, files)
+	}
+
+	changes := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-events:
+				timer.Reset(watchDebounce)
+			case <-timer.C:
+				select {
+				case changes <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// notifyEvents uses file system notifications (e.g. inotify) to watch the
+// files. The directories containing the files are watched, since many editors
+// replace a file on save instead of writing to it.
+func notifyEvents(ctx context.Context, files []string) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	watched := make(map[string]bool, len(files))
+	for _, file := range files {
+		file, err = filepath.Abs(file)
+		if err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+		watched[file] = true
+
+		err = watcher.Add(filepath.Dir(file))
+		if err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+
+	events := make(chan struct{}, 1)
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !watched[filepath.Clean(event.Name)] || event.Has(fsnotify.Chmod) {
+					continue
+				}
+				notify(events)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// pollEvents checks the modification time and the size of the files every
+// watchPollInterval.
+func pollEvents(ctx context.Context, files []string) <-chan struct{} {
+	type state struct {
+		modTime time.Time
+		size    int64
+	}
+
+	stat := func() []state {
+		states := make([]state, len(files))
+		for i, file := range files {
+			fi, err := os.Stat(file)
+			if err != nil {
+				continue
+			}
+			states[i] = state{modTime: fi.ModTime(), size: fi.Size()}
+		}
+		return states
+	}
+
+	events := make(chan struct{}, 1)
+	go func() {
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+
+		last := stat()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := stat()
+				for i := range current {
+					if current[i] != last[i] {
+						notify(events)
+						break
+					}
+				}
+				last = current
+			}
+		}
+	}()
+
+	return events
+}
+
+// notify sends a value to the buffered channel without blocking.
+func notify(events chan<- struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/breml/jsondiffprinter/internal/require"
+)
+
+func TestWatchFilesDebounce(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.json":     `{"a": 0}`,
+		"other.json": `{}`,
+	})
+	file := filepath.Join(dir, "a.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := watchFiles(ctx, []string{file})
+
+	// Changes to other files in the same directory are ignored.
+	err := os.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"a": 1}`), 0o600)
+	require.NoError(t, err)
+	expectNoEvent(t, changes, 3*watchDebounce)
+
+	// Writes in quick succession result in a single change.
+	for i := range 5 {
+		err := os.WriteFile(file, []byte(`{"a": `+strconv.Itoa(i+1)+`}`), 0o600)
+		require.NoError(t, err)
+		time.Sleep(watchDebounce / 5)
+	}
+
+	expectEvent(t, changes, 5*time.Second)
+	expectNoEvent(t, changes, 3*watchDebounce)
+}
+
+func TestPollEvents(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.json": `{"a": 1}`,
+	})
+	file := filepath.Join(dir, "a.json")
+	missing := filepath.Join(dir, "b.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := pollEvents(ctx, []string{file, missing})
+
+	expectNoEvent(t, events, 2*watchPollInterval+watchPollInterval/2)
+
+	err := os.WriteFile(file, []byte(`{"a": 10}`), 0o600)
+	require.NoError(t, err)
+	expectEvent(t, events, 4*watchPollInterval)
+
+	err = os.WriteFile(missing, []byte(`{}`), 0o600)
+	require.NoError(t, err)
+	expectEvent(t, events, 4*watchPollInterval)
+
+	cancel()
+	err = os.WriteFile(file, []byte(`{"a": 100}`), 0o600)
+	require.NoError(t, err)
+	expectNoEvent(t, events, 2*watchPollInterval)
+}
+
+func expectEvent(t *testing.T, events <-chan struct{}, timeout time.Duration) {
+	t.Helper()
+
+	select {
+	case <-events:
+	case <-time.After(timeout):
+		t.Fatalf("want event within %s, got none", timeout)
+	}
+}
+
+func expectNoEvent(t *testing.T, events <-chan struct{}, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case <-events:
+		t.Fatalf("want no event within %s, got one", wait)
+	case <-time.After(wait):
+	}
+}
@@ -7,6 +7,7 @@ const (
 	colorRed      = "\033[31m"
 	colorGreen    = "\033[32m"
 	colorYellow   = "\033[33m"
+	colorCyan     = "\033[36m"
 	colorDarkGrey = "\033[90m"
 )
 
@@ -26,6 +27,10 @@ func (c colorize) yellow(str string) string {
 	return c.colorize(colorYellow, str)
 }
 
+func (c colorize) cyan(str string) string {
+	return c.colorize(colorCyan, str)
+}
+
 func (c colorize) darkGrey(str string) string {
 	return c.colorize(colorDarkGrey, str)
 }
@@ -34,6 +34,16 @@ const (
 // transforms the first document into the second document.
 type Comparer func(before, after any) ([]byte, error)
 
+// A ValueRenderer renders a scalar JSON value (string, float64, bool or nil)
+// for the output. If the renderer returns false, the value is rendered by the
+// next matching renderer or in the default way.
+type ValueRenderer func(v any) (string, bool)
+
+type valueRenderer struct {
+	pattern jsonpointer.Pointer
+	render  ValueRenderer
+}
+
 type Patch = jsonpatch.Patch
 
 // A PatchSeriesPostProcessor processes the JSON patch series before the
@@ -61,6 +71,22 @@ type formatter struct {
 	jsonInJSONStart                      string
 	jsonInJSONEnd                        string
 	patchSeriesPostProcess               PatchSeriesPostProcessor
+	valueRenderers                       []valueRenderer
+	normalizers                          []Normalizer
+	unknownPaths                         []jsonpointer.Pointer
+	unknownPlaceholder                   string
+	block                                *Block
+	blockPaths                           []jsonpointer.Pointer
+	hcl                                  bool
+	collectionHints                      []collectionHint
+	summary                              bool
+
+	// depthOffset is the number of levels of the path, which do not add to
+	// the indentation, e.g. the array indices of nested blocks.
+	depthOffset int
+	// jsonInJSONRoot is true, if printPatch is called for the root of an
+	// embedded JSON document.
+	jsonInJSONRoot bool
 }
 
 type valueType int
@@ -75,6 +101,10 @@ func (v valueType) leftBracket(f formatter) string {
 		return f.jsonInJSONStart
 	case valueTypeArray:
 		return "["
+	case valueTypeSet:
+		return "toset(["
+	case valueTypeList:
+		return "tolist(["
 	case valueTypeJSONinJSONArray:
 		return f.jsonInJSONStart
 	default:
@@ -92,6 +122,8 @@ func (v valueType) rightBracket(f formatter) string {
 		return f.jsonInJSONEnd
 	case valueTypeArray:
 		return "]"
+	case valueTypeSet, valueTypeList:
+		return "])"
 	case valueTypeJSONinJSONArray:
 		return f.jsonInJSONEnd
 	default:
@@ -105,6 +137,8 @@ const (
 	valueTypeJSONinJSONObject
 	valueTypeArray
 	valueTypeJSONinJSONArray
+	valueTypeSet
+	valueTypeList
 )
 
 func (v valueType) notePos() notePosition {
@@ -115,7 +149,7 @@ func (v valueType) notePos() notePosition {
 		return notePositionKey
 	case valueTypeJSONinJSONObject:
 		return notePositionEnd
-	case valueTypeArray:
+	case valueTypeArray, valueTypeSet, valueTypeList:
 		return notePositionKey
 	case valueTypeJSONinJSONArray:
 		return notePositionEnd
@@ -149,6 +183,26 @@ const (
 //
 // Format accepts Options to configure the format and the destination.
 func Format(original any, jsonpatch any, options ...Option) error {
+	_, err := FormatWithResult(original, jsonpatch, options...)
+	return err
+}
+
+// Result contains the details about a formatted diff.
+type Result struct {
+	// Changed is true, if the diff contains at least one change.
+	Changed bool
+	// Stats contains the number of values per kind of change.
+	Stats Stats
+	// ChangedPaths contains the JSON pointers of all the changed values in
+	// the order they appear in the diff.
+	ChangedPaths []string
+	// BytesWritten is the number of bytes written to the writer.
+	BytesWritten int64
+}
+
+// FormatWithResult works like Format and additionally returns the details
+// about the formatted diff, e.g. if the diff contains any changes.
+func FormatWithResult(original any, jsonpatch any, options ...Option) (Result, error) {
 	f := formatter{
 		w: os.Stdout,
 		c: colorize{
@@ -161,48 +215,92 @@ func Format(original any, jsonpatch any, options ...Option) error {
 		keyQuote:          keyQuoteJSON,
 		jsonInJSONStart:   jsonInJSONStartJSON,
 		jsonInJSONEnd:     jsonInJSONEndJSON,
+
+		unknownPlaceholder: defaultUnknownPlaceholder,
 	}
 
 	for _, option := range options {
 		option(&f)
 	}
 
+	if f.block != nil {
+		// Make room for the symbols of the actions, which are wider than the
+		// diff markers.
+		f.prefix += "  "
+	}
+
+	cw := &countingWriter{w: f.w}
+	f.w = cw
+
 	originalPatchTestSeries, err := f.asPatchTestSeries(original, jsonpointer.NewPointer())
 	if err != nil {
-		return fmt.Errorf("failed to convert JSON document to JSON patch series: %w", err)
+		return Result{}, fmt.Errorf("failed to convert JSON document to JSON patch series: %w", err)
 	}
 	patch, err := f.patchFromAny(jsonpatch)
 	if err != nil {
-		return fmt.Errorf("failed to process JSON patch: %w", err)
+		return Result{}, fmt.Errorf("failed to process JSON patch: %w", err)
 	}
 	diff, err := f.compileDiffPatchSeries(originalPatchTestSeries, patch)
 	if err != nil {
-		return fmt.Errorf("failed to compile diff patch series: %w", err)
+		return Result{}, fmt.Errorf("failed to compile diff patch series: %w", err)
 	}
+	diff, err = f.normalize(diff)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to normalize diff patch series: %w", err)
+	}
+	diff = f.markUnknown(diff)
 
 	if f.patchSeriesPostProcess != nil {
 		diff = f.patchSeriesPostProcess(diff)
 	}
 
+	f.printBlockComment()
 	f.printPatch(diff, nil, false)
-	return nil
+
+	result := Result{
+		Stats:        computeStats(diff),
+		ChangedPaths: changedPaths(diff),
+	}
+	result.Changed = len(result.ChangedPaths) > 0
+
+	if f.summary {
+		fmt.Fprintf(f.w, "\n%s\n", result.Stats)
+	}
+
+	result.BytesWritten = cw.n
+	return result, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
 }
 
 func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Pointer, isArray bool) (int, bool) {
 	var i int
 	var hasChange bool
 	var unchangedAttributes int
+	var unchangedBlocks int
 
 	if len(patch) == 0 {
 		return 0, false
 	}
 
+	jsonInJSONRoot := f.jsonInJSONRoot
+	f.jsonInJSONRoot = false
+
 	var preDiffMarkerIndent, indent string
 	if f.indentedDiffMarkers {
-		preDiffMarkerIndent = f.prefix + strings.Repeat(f.indentation, len(patch[0].Path))
+		preDiffMarkerIndent = f.prefix + f.indentFor(patch[0].Path)
 	} else {
 		preDiffMarkerIndent = f.prefix
-		indent = strings.Repeat(f.indentation, len(patch[0].Path))
+		indent = f.indentFor(patch[0].Path)
 	}
 
 	for i = 0; i < len(patch); i++ {
@@ -215,9 +313,20 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 
 		currentKey := ""
 		if !currentPath.IsEmpty() && !isArray {
-			currentKey = fmt.Sprintf("%s%s%s%s", f.keyQuote, currentPath[len(currentPath)-1], f.keyQuote, f.keyValueSeparator)
+			currentKey = f.formatKey(currentPath[len(currentPath)-1]) + f.keyValueSeparator
 		}
 		withKey := !currentPath.IsEmpty() || !f.omitChangeIndicatorOnEmptyKey
+		isBlock := currentPath.IsEmpty() && f.block != nil
+		if isBlock {
+			currentKey = f.block.key()
+			withKey = true
+		}
+
+		if blocks, ok := f.blockOperations(op); ok && !isArray {
+			hasChange = true
+			f.printBlocks(blocks, currentPath, preDiffMarkerIndent, indent)
+			continue
+		}
 
 		switch op.Operation {
 		case jsonpatch.OperationTest:
@@ -227,6 +336,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 				fNew := f
 				fNew.w = buf
 				fNew.prefix += fNew.indentation
+				fNew.jsonInJSONRoot = true
 
 				endIndex := i + 1
 				for ; endIndex < len(patch); endIndex++ {
@@ -267,7 +377,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 				ii, changed := fNew.printPatch(patch[i+1:], currentPath, false)
 				i += ii
 
-				if f.hideUnchanged && !changed {
+				if f.hideUnchanged && !changed && !isBlock {
 					unchangedAttributes++
 					continue
 				}
@@ -288,6 +398,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 				fNew := f
 				fNew.w = buf
 				fNew.prefix += fNew.indentation
+				fNew.jsonInJSONRoot = true
 
 				endIndex := i + 1
 				for ; endIndex < len(patch); endIndex++ {
@@ -321,6 +432,14 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 				})
 
 			case []any:
+				if !isArray && f.isBlockPath(currentPath) && isBlockSeries(patch[i+1:], currentPath) {
+					ii, changed, hidden := f.printBlocks(patch[i+1:], currentPath, preDiffMarkerIndent, indent)
+					i += ii
+					hasChange = hasChange || changed
+					unchangedBlocks += hidden
+					continue
+				}
+
 				buf := &bytes.Buffer{}
 				fNew := f
 				fNew.w = buf
@@ -328,7 +447,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 				ii, changed := fNew.printPatch(patch[i+1:], currentPath, true)
 				i += ii
 
-				if f.hideUnchanged && !changed {
+				if f.hideUnchanged && !changed && !isBlock {
 					unchangedAttributes++
 					continue
 				}
@@ -339,7 +458,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 					indent:              indent,
 					key:                 currentKey,
 					value:               buf.String(),
-					valType:             valueTypeArray,
+					valType:             f.arrayValueType(currentPath),
 					op:                  op,
 					withKey:             true,
 				})
@@ -350,7 +469,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 					continue
 				}
 
-				v := f.formatIndent(op.Value, strings.Repeat(f.indentation, len(currentPath)), f.opTypeIndicator(op.Operation))
+				v := f.formatIndent(op.Value, currentPath, f.indentFor(currentPath), f.opTypeIndicator(op.Operation))
 				f.printOp(printOpConfig{
 					preDiffMarkerIndent: preDiffMarkerIndent,
 					indent:              indent,
@@ -363,7 +482,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 
 		case jsonpatch.OperationAdd:
 			hasChange = true
-			v := f.formatIndent(op.Value, strings.Repeat(f.indentation, len(currentPath)), f.opTypeIndicator(op.Operation))
+			v := f.formatIndent(op.Value, currentPath, f.indentFor(currentPath), f.opTypeIndicator(op.Operation))
 			f.printOp(printOpConfig{
 				preDiffMarkerIndent: preDiffMarkerIndent,
 				indent:              indent,
@@ -375,7 +494,7 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 
 		case jsonpatch.OperationRemove:
 			hasChange = true
-			v := f.formatIndent(op.OldValue, strings.Repeat(f.indentation, len(currentPath)), f.opTypeIndicator(op.Operation))
+			v := f.formatIndent(op.OldValue, currentPath, f.indentFor(currentPath), f.opTypeIndicator(op.Operation))
 			f.printOp(printOpConfig{
 				preDiffMarkerIndent: preDiffMarkerIndent,
 				indent:              indent,
@@ -388,8 +507,8 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 		case jsonpatch.OperationReplace:
 			hasChange = true
 
-			vold := f.formatIndent(op.OldValue, strings.Repeat(f.indentation, len(currentPath)), f.opTypeIndicator(jsonpatch.OperationRemove))
-			v := f.formatIndent(op.Value, strings.Repeat(f.indentation, len(currentPath)), f.opTypeIndicator(jsonpatch.OperationAdd))
+			vold := f.formatIndent(op.OldValue, currentPath, f.indentFor(currentPath), f.opTypeIndicator(jsonpatch.OperationRemove))
+			v := f.formatIndent(op.Value, currentPath, f.indentFor(currentPath), f.opTypeIndicator(jsonpatch.OperationAdd))
 			f.printOp(printOpConfig{
 				preDiffMarkerIndent: preDiffMarkerIndent,
 				indent:              indent,
@@ -401,7 +520,12 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 				withKey:             withKey,
 			})
 		}
-		fmt.Fprintln(f.w, f.printCommaOrNot(i, patch, op))
+		comma := f.printCommaOrNot(i, patch, op)
+		if f.hcl && isArray && !jsonInJSONRoot {
+			// The elements of tuples are separated by commas in HCL.
+			comma = ","
+		}
+		fmt.Fprintln(f.w, comma)
 	}
 
 	if unchangedAttributes > 0 {
@@ -409,6 +533,18 @@ func (f formatter) printPatch(patch jsonpatch.Patch, parentPath jsonpointer.Poin
 		fmt.Fprintf(f.w, "%s%s  %s\n", preDiffMarkerIndent, indent, unchanged)
 	}
 
+	if unchangedBlocks > 0 {
+		if unchangedAttributes > 0 {
+			fmt.Fprintln(f.w)
+		}
+		noun := "blocks"
+		if unchangedBlocks == 1 {
+			noun = "block"
+		}
+		unchanged := f.c.darkGrey(fmt.Sprintf("# (%d unchanged %s hidden)", unchangedBlocks, noun))
+		fmt.Fprintf(f.w, "%s%s  %s\n", preDiffMarkerIndent, indent, unchanged)
+	}
+
 	return i, hasChange
 }
 
@@ -425,7 +561,10 @@ type printOpConfig struct {
 }
 
 func (f formatter) printOp(cfg printOpConfig) {
-	if cfg.op.Operation == jsonpatch.OperationReplace && !f.singleLineReplace {
+	// A heredoc can not be followed by the transition indicator, therefore
+	// replaced multi-line strings are printed as removed and added values.
+	singleLineReplace := f.singleLineReplace && !(f.hcl && (isHeredoc(cfg.valueOld) || isHeredoc(cfg.value)))
+	if cfg.op.Operation == jsonpatch.OperationReplace && !singleLineReplace {
 		if cfg.valType != valueTypeJSONinJSONObject && cfg.valType != valueTypeJSONinJSONArray {
 			op := cfg.op.Clone()
 			op.Operation = jsonpatch.OperationRemove
@@ -470,13 +609,18 @@ func (f formatter) printOp(cfg printOpConfig) {
 		endNote = cfg.op.Metadata["note"]
 	}
 
+	preDiffMarkerIndent := cfg.preDiffMarkerIndent
 	opTypeIndicator := f.opTypeIndicator(cfg.op.Operation)
 	if cfg.op.Metadata["operationOverride"] != "" {
 		opTypeIndicator = f.opTypeIndicator(jsonpatch.OperationType(cfg.op.Metadata["operationOverride"]))
 	}
+	if cfg.op.Path.IsEmpty() && f.block != nil {
+		preDiffMarkerIndent = strings.TrimSuffix(preDiffMarkerIndent, "  ")
+		opTypeIndicator = f.actionIndicator(f.block.Action)
+	}
 
 	if cfg.withKey {
-		fmt.Fprintf(f.w, "%s%s %s%s%s", cfg.preDiffMarkerIndent, opTypeIndicator, cfg.indent, cfg.key, leftBracket)
+		fmt.Fprintf(f.w, "%s%s %s%s%s", preDiffMarkerIndent, opTypeIndicator, cfg.indent, cfg.key, leftBracket)
 	} else {
 		fmt.Fprint(f.w, "  ")
 	}
@@ -504,6 +648,11 @@ func (f formatter) opTypeIndicator(opType jsonpatch.OperationType) string {
 	}
 }
 
+// indentFor returns the indentation for the value located at path.
+func (f formatter) indentFor(path jsonpointer.Pointer) string {
+	return strings.Repeat(f.indentation, len(path)-f.depthOffset)
+}
+
 // printCommaOrNot prints a comma if the next operation is in the same path.
 func (f formatter) printCommaOrNot(i int, patch jsonpatch.Patch, op jsonpatch.Operation) string {
 	if !f.commas {
@@ -520,20 +669,20 @@ func (f formatter) printCommaOrNot(i int, patch jsonpatch.Patch, op jsonpatch.Op
 	return ""
 }
 
-func (f formatter) formatIndent(v any, prefix string, operation string) string {
+func (f formatter) formatIndent(v any, path jsonpointer.Pointer, prefix string, operation string) string {
 	switch vt := v.(type) {
 	case jsonInJSONObject:
 		sb := strings.Builder{}
 		sb.WriteString(f.jsonInJSONStart + "\n")
 		sb.WriteString(f.prefix + prefix + f.indentation + "  ")
-		sb.WriteString(f.formatIndent(map[string]any(vt), prefix+f.indentation, operation))
+		sb.WriteString(f.formatIndent(map[string]any(vt), path, prefix+f.indentation, operation))
 		sb.WriteString("\n" + f.prefix + prefix + "  " + f.jsonInJSONEnd)
 		return sb.String()
 	case jsonInJSONArray:
 		sb := strings.Builder{}
 		sb.WriteString(f.jsonInJSONStart + "\n")
 		sb.WriteString(f.prefix + prefix + f.indentation + "  ")
-		sb.WriteString(f.formatIndent([]any(vt), prefix+f.indentation, operation))
+		sb.WriteString(f.formatIndent([]any(vt), path, prefix+f.indentation, operation))
 		sb.WriteString("\n" + f.prefix + prefix + "  " + f.jsonInJSONEnd)
 		return sb.String()
 	case map[string]any:
@@ -542,6 +691,13 @@ func (f formatter) formatIndent(v any, prefix string, operation string) string {
 
 		for i, k := range keys(vt) {
 			v := vt[k]
+			if elements, ok := f.blockElements(v, path.AppendKey(k)); ok {
+				if len(elements) > 0 {
+					sb.WriteString(f.formatBlocks(elements, k, path.AppendKey(k), prefix, operation))
+					sb.WriteString("\n")
+				}
+				continue
+			}
 			if !f.indentedDiffMarkers {
 				sb.WriteString(operation)
 				sb.WriteString(" ")
@@ -552,11 +708,9 @@ func (f formatter) formatIndent(v any, prefix string, operation string) string {
 				sb.WriteString(operation)
 				sb.WriteString(" ")
 			}
-			sb.WriteString(f.keyQuote)
-			sb.WriteString(k)
-			sb.WriteString(f.keyQuote)
+			sb.WriteString(f.formatKey(k))
 			sb.WriteString(f.keyValueSeparator)
-			sb.WriteString(f.formatIndent(v, prefix+f.indentation, operation))
+			sb.WriteString(f.formatIndent(v, path.AppendKey(k), prefix+f.indentation, operation))
 			if f.commas && i < len(vt)-1 {
 				sb.WriteString(",")
 			}
@@ -569,8 +723,9 @@ func (f formatter) formatIndent(v any, prefix string, operation string) string {
 		return sb.String()
 
 	case []any:
+		valType := f.arrayValueType(path)
 		sb := strings.Builder{}
-		sb.WriteString("[\n")
+		sb.WriteString(valType.leftBracket(f) + "\n")
 
 		for i, v := range vt {
 			if !f.indentedDiffMarkers {
@@ -583,19 +738,29 @@ func (f formatter) formatIndent(v any, prefix string, operation string) string {
 				sb.WriteString(operation)
 				sb.WriteString(" ")
 			}
-			sb.WriteString(f.formatIndent(v, prefix+f.indentation, operation))
-			if f.commas && i < len(vt)-1 {
+			sb.WriteString(f.formatIndent(v, path.AppendIndex(i), prefix+f.indentation, operation))
+			if f.hcl || f.commas && i < len(vt)-1 {
 				sb.WriteString(",")
 			}
 			sb.WriteString("\n")
 		}
 
 		sb.WriteString(f.prefix + prefix + "  ")
-		sb.WriteString("]")
+		sb.WriteString(valType.rightBracket(f))
 
 		return sb.String()
 
+	case unknownValue:
+		return f.unknownPlaceholder
+
 	default:
+		if rendered, ok := f.renderValue(vt, path); ok {
+			return rendered
+		}
+		if s, ok := vt.(string); ok && f.hcl {
+			return f.formatHCLString(s, prefix)
+		}
+
 		sb := strings.Builder{}
 		encoder := json.NewEncoder(&sb)
 		encoder.SetIndent(f.prefix+prefix+"  ", f.indentation)
@@ -609,6 +774,46 @@ func (f formatter) formatIndent(v any, prefix string, operation string) string {
 	}
 }
 
+// renderValue renders the scalar value v using the first value renderer,
+// which is registered for a pattern matching path and which accepts the value.
+func (f formatter) renderValue(v any, path jsonpointer.Pointer) (string, bool) {
+	for _, vr := range f.valueRenderers {
+		if !path.Matches(vr.pattern) {
+			continue
+		}
+		if rendered, ok := vr.render(v); ok {
+			return rendered, true
+		}
+	}
+	return "", false
+}
+
+// formatKey formats the key of an object member. In the style of JSON, the
+// key is escaped like any other JSON string, in the style of Terraform, the
+// key is only quoted, if it is not a valid identifier in HCL.
+func (f formatter) formatKey(key string) string {
+	switch {
+	case f.hcl && isHCLIdentifier(key) && !hclKeywords[key]:
+		return key
+	case f.hcl:
+		return quoteHCL(key)
+	case f.keyQuote == keyQuoteJSON:
+		return quoteJSON(key)
+	default:
+		return f.keyQuote + key + f.keyQuote
+	}
+}
+
+// quoteJSON returns s as quoted JSON string.
+func quoteJSON(s string) string {
+	sb := strings.Builder{}
+	encoder := json.NewEncoder(&sb)
+	encoder.SetEscapeHTML(false)
+	// Encoding a string does never fail.
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
 func keys(m map[string]any) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
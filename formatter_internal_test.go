@@ -201,3 +201,56 @@ func Test_compileDiffPatchSeries(t *testing.T) {
 		})
 	}
 }
+
+func Test_matchKeyCase(t *testing.T) {
+	tests := []struct {
+		name string
+
+		a map[string]any
+		b map[string]any
+
+		want map[string]any
+	}{
+		{
+			name: "renamed",
+			a:    map[string]any{"Name": 1, "id": 1},
+			b:    map[string]any{"name": 2, "id": 2},
+
+			want: map[string]any{"Name": 2, "id": 2},
+		},
+		{
+			name: "exact key exists",
+			a:    map[string]any{"Name": 1, "name": 1},
+			b:    map[string]any{"name": 2},
+
+			want: map[string]any{"name": 2},
+		},
+		{
+			name: "several keys in a",
+			a:    map[string]any{"Name": 1, "NAME": 1},
+			b:    map[string]any{"name": 2},
+
+			want: map[string]any{"name": 2},
+		},
+		{
+			name: "several keys in b",
+			a:    map[string]any{"Name": 1},
+			b:    map[string]any{"name": 2, "NAME": 3},
+
+			want: map[string]any{"name": 2, "NAME": 3},
+		},
+		{
+			name: "no counterpart",
+			a:    map[string]any{"a": 1},
+			b:    map[string]any{"b": 2},
+
+			want: map[string]any{"b": 2},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, matchKeyCase(tc.a, tc.b))
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/txtar"
@@ -174,3 +175,907 @@ func metadataByJSONPointer(t *testing.T, metadata map[string]map[string]string)
 		return diff
 	}
 }
+
+func TestFormatterValueRenderer(t *testing.T) {
+	before := []byte(`{"name":"alert","thresholdMs":1500,"nested":[{"thresholdMs":250}]}`)
+	patch := []byte(`[
+		{"op":"replace","path":"/thresholdMs","value":2000},
+		{"op":"add","path":"/nested/1","value":{"thresholdMs":750}}
+	]`)
+
+	milliseconds := func(v any) (string, bool) {
+		ms, ok := v.(float64)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%gs", ms/1000), true
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+
+		want string
+	}{
+		{
+			name:    "exact path",
+			pattern: "/thresholdMs",
+
+			want: `  {
+    "name": "alert",
+    "nested": [
+      {
+        "thresholdMs": 250
+      },
++     {
++       "thresholdMs": 750
+      }
+    ],
+-   "thresholdMs": 1.5s
++   "thresholdMs": 2s
+  }
+`,
+		},
+		{
+			name:    "wildcard",
+			pattern: "/**/thresholdMs",
+
+			want: `  {
+    "name": "alert",
+    "nested": [
+      {
+        "thresholdMs": 0.25s
+      },
++     {
++       "thresholdMs": 0.75s
+      }
+    ],
+-   "thresholdMs": 1.5s
++   "thresholdMs": 2s
+  }
+`,
+		},
+		{
+			name:    "renderer declines",
+			pattern: "/name",
+
+			want: `  {
+    "name": "alert",
+    "nested": [
+      {
+        "thresholdMs": 250
+      },
++     {
++       "thresholdMs": 750
+      }
+    ],
+-   "thresholdMs": 1500
++   "thresholdMs": 2000
+  }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := jsondiffprinter.Format(before, patch,
+				jsondiffprinter.WithWriter(&buf),
+				jsondiffprinter.WithValueRenderer(tc.pattern, milliseconds),
+			)
+			require.NoError(t, err)
+
+			require.EqualStringWithTabwriter(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestFormatterNormalizers(t *testing.T) {
+	before := []byte(`{"description":"","labels":{"Env":"prod"},"name":"web ","ratio":0.5,"tags":["a","b"],"owner":null}`)
+	patch := []byte(`[
+		{"op":"remove","path":"/description"},
+		{"op":"remove","path":"/labels/Env"},
+		{"op":"add","path":"/labels/env","value":"prod"},
+		{"op":"replace","path":"/name","value":"web"},
+		{"op":"remove","path":"/owner"},
+		{"op":"replace","path":"/ratio","value":0.5000001},
+		{"op":"replace","path":"/tags/0","value":"b"},
+		{"op":"replace","path":"/tags/1","value":"a"}
+	]`)
+
+	tests := []struct {
+		name        string
+		normalizers []jsondiffprinter.Normalizer
+
+		want string
+	}{
+		{
+			name: "without normalizers",
+
+			want: `  {
+-   "description": "",
+    "labels": {
+-     "Env": "prod",
++     "env": "prod"
+    },
+-   "name": "web ",
++   "name": "web",
+-   "owner": null,
+-   "ratio": 0.5,
++   "ratio": 0.5000001,
+    "tags": [
+-     "a",
++     "b",
+-     "b"
++     "a"
+    ]
+  }
+`,
+		},
+		{
+			name: "all normalizers",
+			normalizers: []jsondiffprinter.Normalizer{
+				jsondiffprinter.EmptyAsMissing("/description", "/owner"),
+				jsondiffprinter.TrimWhitespace(),
+				jsondiffprinter.NumericTolerance(0.001, "/ratio"),
+				jsondiffprinter.UnorderedArrays("/tags"),
+				jsondiffprinter.CaseInsensitiveKeys("/labels"),
+			},
+
+			want: `  {
+    "description": "",
+    "labels": {
+      "Env": "prod"
+    },
+    "name": "web ",
+    "owner": null,
+    "ratio": 0.5,
+    "tags": [
+      "a",
+      "b"
+    ]
+  }
+`,
+		},
+		{
+			name: "ignore paths",
+			normalizers: []jsondiffprinter.Normalizer{
+				jsondiffprinter.IgnorePaths("/description", "/name", "/tags/*"),
+			},
+
+			want: `  {
+    "description": "",
+    "labels": {
+-     "Env": "prod",
++     "env": "prod"
+    },
+    "name": "web ",
+-   "owner": null,
+-   "ratio": 0.5,
++   "ratio": 0.5000001,
+    "tags": [
+      "a",
+      "b"
+    ]
+  }
+`,
+		},
+		{
+			name: "normalizers not matching",
+			normalizers: []jsondiffprinter.Normalizer{
+				jsondiffprinter.EmptyAsMissing("/name"),
+				jsondiffprinter.TrimWhitespace("/description"),
+				jsondiffprinter.NumericTolerance(0.00000001, "/ratio"),
+				jsondiffprinter.UnorderedArrays("/other"),
+				jsondiffprinter.CaseInsensitiveKeys("/tags"),
+			},
+
+			want: `  {
+-   "description": "",
+    "labels": {
+-     "Env": "prod",
++     "env": "prod"
+    },
+-   "name": "web ",
++   "name": "web",
+-   "owner": null,
+-   "ratio": 0.5,
++   "ratio": 0.5000001,
+    "tags": [
+-     "a",
++     "b",
+-     "b"
++     "a"
+    ]
+  }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := jsondiffprinter.Format(before, patch,
+				jsondiffprinter.WithWriter(&buf),
+				jsondiffprinter.WithNormalizers(tc.normalizers...),
+			)
+			require.NoError(t, err)
+
+			require.EqualStringWithTabwriter(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestFormatterUnknownPaths(t *testing.T) {
+	before := []byte(`{"id":"i-123","name":"web","tags":{"env":"prod","owner":"ops"}}`)
+	patch := []byte(`[
+		{"op":"replace","path":"/name","value":"app"},
+		{"op":"add","path":"/arn","value":null}
+	]`)
+
+	tests := []struct {
+		name    string
+		options []jsondiffprinter.Option
+
+		want string
+	}{
+		{
+			name: "json",
+			options: []jsondiffprinter.Option{
+				jsondiffprinter.WithUnknownPaths("/arn", "/id"),
+			},
+
+			want: `  {
++   "arn": (known after apply),
+-   "id": "i-123",
++   "id": (known after apply),
+-   "name": "web",
++   "name": "app",
+    "tags": {
+      "env": "prod",
+      "owner": "ops"
+    }
+  }
+`,
+		},
+		{
+			name: "custom placeholder",
+			options: []jsondiffprinter.Option{
+				jsondiffprinter.WithUnknownPaths("/arn"),
+				jsondiffprinter.WithUnknownPlaceholder("<computed>"),
+			},
+
+			want: `  {
++   "arn": <computed>,
+    "id": "i-123",
+-   "name": "web",
++   "name": "app",
+    "tags": {
+      "env": "prod",
+      "owner": "ops"
+    }
+  }
+`,
+		},
+		{
+			name: "nested in object",
+			options: []jsondiffprinter.Option{
+				jsondiffprinter.WithUnknownPaths("/tags/*"),
+			},
+
+			want: `  {
++   "arn": null,
+    "id": "i-123",
+-   "name": "web",
++   "name": "app",
+~   "tags": {
+-     "env": "prod",
++     "env": (known after apply),
+-     "owner": "ops"
++     "owner": (known after apply)
+    }
+  }
+`,
+		},
+		{
+			name: "terraform",
+			options: []jsondiffprinter.Option{
+				jsondiffprinter.WithTerraformDefaults(),
+				jsondiffprinter.WithUnknownPaths("/arn", "/id", "/tags/owner"),
+			},
+
+			want: `  {
+    + arn = (known after apply)
+    ~ id = "i-123" -> (known after apply)
+    ~ name = "web" -> "app"
+    ~ tags = {
+        ~ owner = "ops" -> (known after apply)
+          # (1 unchanged attribute hidden)
+      }
+  }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			options := append([]jsondiffprinter.Option{jsondiffprinter.WithWriter(&buf)}, tc.options...)
+			err := jsondiffprinter.Format(before, patch, options...)
+			require.NoError(t, err)
+
+			require.EqualStringWithTabwriter(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestFormatterBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+		block    jsondiffprinter.Block
+		// options replace WithTerraformDefaults, if set.
+		options []jsondiffprinter.Option
+
+		want string
+	}{
+		{
+			name:     "update",
+			original: `{"ami":"ami-1","id":"i-1"}`,
+			patch:    `[{"op":"replace","path":"/ami","value":"ami-2"}]`,
+			block: jsondiffprinter.Block{
+				Address: "aws_instance.web",
+				Type:    "resource",
+				Labels:  []string{"aws_instance", "web"},
+				Action:  jsondiffprinter.ActionUpdate,
+			},
+
+			want: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+      ~ ami = "ami-1" -> "ami-2"
+        # (1 unchanged attribute hidden)
+    }
+`,
+		},
+		{
+			name:     "create",
+			original: `null`,
+			patch:    `[{"op":"add","path":"","value":{"ami":"ami-1","tags":{"env":"prod"}}}]`,
+			block: jsondiffprinter.Block{
+				Address: "aws_instance.web",
+				Type:    "resource",
+				Labels:  []string{"aws_instance", "web"},
+				Action:  jsondiffprinter.ActionCreate,
+			},
+
+			want: `  # aws_instance.web will be created
+  + resource "aws_instance" "web" {
+      + ami = "ami-1"
+      + tags = {
+          + env = "prod"
+        }
+    }
+`,
+		},
+		{
+			name:     "replace",
+			original: `{"ami":"ami-1","id":"i-1"}`,
+			patch:    `[{"op":"replace","path":"/ami","value":"ami-2"}]`,
+			block: jsondiffprinter.Block{
+				Address: "module.app.aws_instance.web",
+				Type:    "resource",
+				Labels:  []string{"aws_instance", "web"},
+				Action:  jsondiffprinter.ActionDeleteThenCreate,
+			},
+
+			want: `  # module.app.aws_instance.web must be replaced
+-/+ resource "aws_instance" "web" {
+      ~ ami = "ami-1" -> "ami-2"
+        # (1 unchanged attribute hidden)
+    }
+`,
+		},
+		{
+			name:     "read without changes",
+			original: `{"id":"ami-1"}`,
+			patch:    `[]`,
+			block: jsondiffprinter.Block{
+				Address: "data.aws_ami.ubuntu",
+				Type:    "data",
+				Labels:  []string{"aws_ami", "ubuntu"},
+				Action:  jsondiffprinter.ActionRead,
+			},
+
+			want: `  # data.aws_ami.ubuntu will be read during apply
+ <= data "aws_ami" "ubuntu" {
+        # (1 unchanged attribute hidden)
+    }
+`,
+		},
+		{
+			name:     "without address",
+			original: `{"id":"i-1"}`,
+			patch:    `[{"op":"remove","path":""}]`,
+			block: jsondiffprinter.Block{
+				Type:   "resource",
+				Labels: []string{"aws_instance", "web"},
+				Action: jsondiffprinter.ActionDelete,
+			},
+
+			want: `  - resource "aws_instance" "web" {
+      - id = "i-1"
+    }
+`,
+		},
+		{
+			name:     "json style",
+			original: `{"ami":"ami-1","tags":{"env":"prod"}}`,
+			patch:    `[{"op":"replace","path":"/ami","value":"ami-2"},{"op":"add","path":"/tags/team","value":"a"}]`,
+			block: jsondiffprinter.Block{
+				Address: "aws_instance.web",
+				Type:    "resource",
+				Labels:  []string{"aws_instance", "web"},
+				Action:  jsondiffprinter.ActionUpdate,
+			},
+			options: []jsondiffprinter.Option{},
+
+			want: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+  -   "ami": "ami-1",
+  +   "ami": "ami-2",
+      "tags": {
+        "env": "prod",
+  +     "team": "a"
+      }
+    }
+`,
+		},
+		{
+			name:     "json style with indented diff markers",
+			original: `{"ami":"ami-1","id":"i-1"}`,
+			patch:    `[{"op":"replace","path":"/ami","value":"ami-2"}]`,
+			block: jsondiffprinter.Block{
+				Address: "aws_instance.web",
+				Type:    "resource",
+				Labels:  []string{"aws_instance", "web"},
+				Action:  jsondiffprinter.ActionDeleteThenCreate,
+			},
+			options: []jsondiffprinter.Option{
+				jsondiffprinter.WithIndentedDiffMarkers(true),
+			},
+
+			want: `  # aws_instance.web must be replaced
+-/+ resource "aws_instance" "web" {
+    - "ami": "ami-1",
+    + "ami": "ami-2",
+      "id": "i-1"
+    }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			options := tc.options
+			if options == nil {
+				options = []jsondiffprinter.Option{jsondiffprinter.WithTerraformDefaults()}
+			}
+			options = append(options, jsondiffprinter.WithWriter(&buf), jsondiffprinter.WithBlock(tc.block))
+			err := jsondiffprinter.Format([]byte(tc.original), []byte(tc.patch), options...)
+			require.NoError(t, err)
+
+			require.EqualStringWithTabwriter(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestFormatterBlockPaths(t *testing.T) {
+	original := `{"ami":"ami-1","ebs":[{"size":10,"type":"gp2"},{"size":20,"type":"gp2"}],"network":[{"id":"eni-1"}],"tags":{"env":"prod"}}`
+
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+
+		want string
+	}{
+		{
+			name:     "changed and added blocks",
+			original: original,
+			patch: `[
+				{"op":"replace","path":"/ebs/0/size","value":15},
+				{"op":"add","path":"/ebs/2","value":{"size":30,"type":"gp3"}}
+			]`,
+
+			want: `  {
+    ~ ebs {
+        ~ size = 10 -> 15
+          # (1 unchanged attribute hidden)
+      }
+    + ebs {
+        + size = 30
+        + type = "gp3"
+      }
+      # (2 unchanged attribute hidden)
+
+      # (2 unchanged blocks hidden)
+  }
+`,
+		},
+		{
+			name:     "removed blocks",
+			original: original,
+			patch: `[
+				{"op":"remove","path":"/ebs"},
+				{"op":"replace","path":"/tags/env","value":"dev"}
+			]`,
+
+			want: `  {
+    - ebs {
+        - size = 10
+        - type = "gp2"
+      }
+    - ebs {
+        - size = 20
+        - type = "gp2"
+      }
+      tags = {
+        ~ env = "prod" -> "dev"
+      }
+      # (1 unchanged attribute hidden)
+
+      # (1 unchanged block hidden)
+  }
+`,
+		},
+		{
+			name:     "added document",
+			original: `null`,
+			patch:    `[{"op":"add","path":"","value":` + original + `}]`,
+
+			want: `  {
+    + ami = "ami-1"
+    + ebs {
+        + size = 10
+        + type = "gp2"
+      }
+    + ebs {
+        + size = 20
+        + type = "gp2"
+      }
+    + network {
+        + id = "eni-1"
+      }
+    + tags = {
+        + env = "prod"
+      }
+  }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := jsondiffprinter.Format([]byte(tc.original), []byte(tc.patch),
+				jsondiffprinter.WithWriter(&buf),
+				jsondiffprinter.WithTerraformDefaults(),
+				jsondiffprinter.WithBlockPaths("/ebs", "/network"),
+			)
+			require.NoError(t, err)
+
+			require.EqualStringWithTabwriter(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestFormatterHCL(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+		options  []jsondiffprinter.Option
+
+		want string
+	}{
+		{
+			name:     "keys",
+			original: `{"name":"web","_private-1":1,"a b":2,"x/y":3,"1":4,"null":5}`,
+			patch:    `[]`,
+
+			want: `  {
+      "1" = 4
+      _private-1 = 1
+      "a b" = 2
+      name = "web"
+      "null" = 5
+      "x/y" = 3
+  }
+`,
+		},
+		{
+			name:     "strings",
+			original: `{"template":"${var.name}-%{if true}x%{endif}","script":"#!/bin/sh\necho \"${HOME}\"\n\nexit 0\n"}`,
+			patch:    `[]`,
+
+			want: `  {
+      script = <<-EOT
+          #!/bin/sh
+          echo "$${HOME}"
+
+          exit 0
+      EOT
+      template = "$${var.name}-%%{if true}x%%{endif}"
+  }
+`,
+		},
+		{
+			name:     "heredoc delimiter in string",
+			original: `{"text":"EOT\nEOT_\n"}`,
+			patch:    `[]`,
+
+			want: `  {
+      text = <<-EOT__
+          EOT
+          EOT_
+      EOT__
+  }
+`,
+		},
+		{
+			name:     "replaced multi-line string",
+			original: `{"script":"echo a\necho b\n","name":"a"}`,
+			patch:    `[{"op":"replace","path":"/script","value":"echo a\necho c\n"},{"op":"replace","path":"/name","value":"line 1\nline 2\n"}]`,
+
+			want: `  {
+    - name = "a"
+    + name = <<-EOT
+          line 1
+          line 2
+      EOT
+    - script = <<-EOT
+          echo a
+          echo b
+      EOT
+    + script = <<-EOT
+          echo a
+          echo c
+      EOT
+  }
+`,
+		},
+		{
+			name:     "multi-line strings without heredoc",
+			original: `{"no_trailing_newline":"line 1\nline 2","indented":"  line 1\n\n\tline 2\n"}`,
+			patch:    `[]`,
+
+			want: `  {
+      indented = "  line 1\n\n\tline 2\n"
+      no_trailing_newline = "line 1\nline 2"
+  }
+`,
+		},
+		{
+			name:     "tuples",
+			original: `{"ports":[80,443]}`,
+			patch:    `[{"op":"add","path":"/ports/2","value":8080}]`,
+
+			want: `  {
+      ports = [
+          80,
+          443,
+        + 8080,
+      ]
+  }
+`,
+		},
+		{
+			name:     "set and list hints",
+			original: `{"security_groups":["sg-1"],"subnets":["subnet-1"]}`,
+			patch:    `[{"op":"add","path":"/security_groups/1","value":"sg-2"},{"op":"remove","path":"/subnets"}]`,
+			options: []jsondiffprinter.Option{
+				jsondiffprinter.WithSetPaths("/security_groups"),
+				jsondiffprinter.WithListPaths("/subnets"),
+			},
+
+			want: `  {
+      security_groups = toset([
+          "sg-1",
+        + "sg-2",
+      ])
+    - subnets = tolist([
+        - "subnet-1",
+      ])
+  }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			options := append([]jsondiffprinter.Option{
+				jsondiffprinter.WithWriter(&buf),
+				jsondiffprinter.WithTerraformDefaults(),
+				jsondiffprinter.WithHideUnchanged(false),
+			}, tc.options...)
+			err := jsondiffprinter.Format([]byte(tc.original), []byte(tc.patch), options...)
+			require.NoError(t, err)
+
+			require.EqualStringWithTabwriter(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestFormatterKeyEscaping(t *testing.T) {
+	original := []byte(`{"say \"hi\"":1,"back\\slash":{"new\nline":[true,{"tab\tkey":"\u0001"}]},"<html>&":"x","plain":null}`)
+
+	t.Run("unchanged document round-trip", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := jsondiffprinter.Format(original, []byte(`[]`),
+			jsondiffprinter.WithWriter(&buf),
+		)
+		require.NoError(t, err)
+
+		// Remove the diff markers, the remaining output is the original
+		// JSON document.
+		sb := strings.Builder{}
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			require.Equal(t, "  ", line[:2])
+			sb.WriteString(line[2:] + "\n")
+		}
+
+		var want, got any
+		err = json.Unmarshal(original, &want)
+		require.NoError(t, err)
+		err = json.Unmarshal([]byte(sb.String()), &got)
+		require.NoError(t, err)
+
+		require.Equal(t, want, got)
+	})
+
+	t.Run("unchanged lines are valid JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := jsondiffprinter.Format(original, []byte(`[{"op":"replace","path":"/plain","value":"changed"}]`),
+			jsondiffprinter.WithWriter(&buf),
+		)
+		require.NoError(t, err)
+
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			if line[0] != ' ' {
+				continue
+			}
+
+			// Complete the fragment of each unchanged line to a JSON
+			// document and check, that it is valid.
+			fragment := strings.TrimSuffix(strings.TrimSpace(line), ",")
+			switch {
+			case strings.HasSuffix(fragment, "{"):
+				fragment += "}"
+			case strings.HasSuffix(fragment, "["):
+				fragment += "]"
+			case fragment == "}" || fragment == "]":
+				continue
+			}
+			if strings.HasPrefix(fragment, `"`) && strings.Contains(fragment, `": `) {
+				fragment = "{" + fragment + "}"
+			}
+
+			if !json.Valid([]byte(fragment)) {
+				t.Errorf("unchanged line %q is not valid JSON", line)
+			}
+		}
+	})
+}
+
+func TestFormatterStats(t *testing.T) {
+	before := []byte(`{"a":1,"b":{"c":true},"d":[1,2],"e":{"f":"g"}}`)
+	patch := []byte(`[
+		{"op":"replace","path":"/a","value":2},
+		{"op":"remove","path":"/b"},
+		{"op":"add","path":"/d/2","value":3},
+		{"op":"add","path":"/h","value":{"i":null}}
+	]`)
+
+	var buf bytes.Buffer
+
+	result, err := jsondiffprinter.FormatWithResult(before, patch,
+		jsondiffprinter.WithWriter(&buf),
+		jsondiffprinter.WithSummary(true),
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, jsondiffprinter.Stats{
+		Added:     jsondiffprinter.Count{Leaves: 1, Containers: 1},
+		Removed:   jsondiffprinter.Count{Containers: 1},
+		Replaced:  jsondiffprinter.Count{Leaves: 1},
+		Unchanged: jsondiffprinter.Count{Leaves: 3, Containers: 1},
+	}, result.Stats)
+
+	want := `  {
+-   "a": 1,
++   "a": 2,
+-   "b": {
+-     "c": true
+    },
+    "d": [
+      1,
+      2,
++     3
+    ],
+    "e": {
+      "f": "g"
+    },
++   "h": {
++     "i": null
+    }
+  }
+
+Summary: 2 added, 1 removed, 1 replaced, 4 unchanged.
+`
+	require.EqualStringWithTabwriter(t, want, buf.String())
+}
+
+func TestFormatterResult(t *testing.T) {
+	before := []byte(`{"a":1,"b":{"c":true}}`)
+
+	tests := []struct {
+		name  string
+		patch []byte
+
+		wantChanged      bool
+		wantChangedPaths []string
+	}{
+		{
+			name:  "no changes",
+			patch: []byte(`[]`),
+
+			wantChanged: false,
+		},
+		{
+			name: "changes",
+			patch: []byte(`[
+				{"op":"replace","path":"/a","value":2},
+				{"op":"remove","path":"/b/c"}
+			]`),
+
+			wantChanged:      true,
+			wantChangedPaths: []string{"/a", "/b/c"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			result, err := jsondiffprinter.FormatWithResult(before, tc.patch, jsondiffprinter.WithWriter(&buf))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantChanged, result.Changed)
+			require.Equal(t, tc.wantChangedPaths, result.ChangedPaths)
+			require.Equal(t, int64(buf.Len()), result.BytesWritten)
+		})
+	}
+}
+
+func TestFormatterTerraformDefaultsWithoutColor(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := jsondiffprinter.Format([]byte(`{"a":1}`), []byte(`[{"op":"replace","path":"/a","value":2}]`),
+		jsondiffprinter.WithTerraformDefaults(),
+		jsondiffprinter.WithWriter(&buf),
+	)
+	require.NoError(t, err)
+
+	require.EqualStringWithTabwriter(t, "  {\n    ~ a = 1 -> 2\n  }\n", buf.String())
+}
@@ -0,0 +1,127 @@
+package jsondiffprinter
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+const heredocDelimiter = "EOT"
+
+// hclKeywords are the identifiers, which have a special meaning in HCL
+// expressions and therefore need to be quoted, if used as object keys.
+var hclKeywords = map[string]bool{
+	"true":  true,
+	"false": true,
+	"null":  true,
+	"for":   true,
+	"in":    true,
+	"if":    true,
+}
+
+type collectionHint struct {
+	pattern   jsonpointer.Pointer
+	valueType valueType
+}
+
+// isHCLIdentifier returns true, if s is a valid identifier in HCL, which
+// consists of letters, digits, underscores and hyphens and does not start
+// with a digit or a hyphen.
+func isHCLIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r), r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// quoteHCL returns s as quoted HCL string literal. Template sequences are
+// escaped, such that the string is not interpreted.
+func quoteHCL(s string) string {
+	return escapeHCLTemplate(quoteJSON(s))
+}
+
+func escapeHCLTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+// formatHCLString formats the string s as HCL string literal. Multi-line
+// strings are formatted as indented heredoc, where the lines are indented by
+// one level relative to prefix. Since a heredoc always ends with a newline and
+// the leading whitespace common to all the lines is removed, strings without
+// a trailing newline or with common leading whitespace are quoted instead.
+func (f formatter) formatHCLString(s string, prefix string) string {
+	if !strings.HasSuffix(s, "\n") || strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\t'
+	}) {
+		return quoteHCL(s)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if hasCommonIndentation(lines) {
+		return quoteHCL(s)
+	}
+
+	delimiter := heredocDelimiter
+	for containsLine(lines, delimiter) {
+		delimiter += "_"
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("<<-" + delimiter + "\n")
+	for _, line := range lines {
+		if line != "" {
+			sb.WriteString(f.prefix + prefix + "  " + f.indentation)
+			sb.WriteString(escapeHCLTemplate(line))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(f.prefix + prefix + "  " + delimiter)
+
+	return sb.String()
+}
+
+// isHeredoc returns true, if the formatted value s is a heredoc.
+func isHeredoc(s string) bool {
+	return strings.HasPrefix(s, "<<-")
+}
+
+// hasCommonIndentation returns true, if all the non-empty lines start with
+// whitespace.
+func hasCommonIndentation(lines []string) bool {
+	for _, line := range lines {
+		if line != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			return false
+		}
+	}
+	return true
+}
+
+func containsLine(lines []string, s string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// arrayValueType returns the value type for the array located at path, which
+// is either a plain array or, if hinted, a set or a list.
+func (f formatter) arrayValueType(path jsonpointer.Pointer) valueType {
+	for _, hint := range f.collectionHints {
+		if path.Matches(hint.pattern) {
+			return hint.valueType
+		}
+	}
+	return valueTypeArray
+}
@@ -125,6 +125,36 @@ func (p Pointer) HasSameAncestorsAs(alt Pointer) bool {
 	return equal(p[:len(p)-1], alt[:len(alt)-1])
 }
 
+// Matches reports whether the pointer matches the given pattern.
+// A pattern is a pointer, where the token "*" matches exactly one arbitrary
+// token and the token "**" matches any number (including zero) of arbitrary
+// tokens.
+func (p Pointer) Matches(pattern Pointer) bool {
+	if len(pattern) == 0 {
+		return len(p) == 0
+	}
+
+	switch pattern[0] {
+	case wildcardAny:
+		for i := 0; i <= len(p); i++ {
+			if p[i:].Matches(pattern[1:]) {
+				return true
+			}
+		}
+		return false
+	case wildcardOne:
+		if len(p) == 0 {
+			return false
+		}
+	default:
+		if len(p) == 0 || p[0] != pattern[0] {
+			return false
+		}
+	}
+
+	return p[1:].Matches(pattern[1:])
+}
+
 func equal(a, b Pointer) bool {
 	if len(a) != len(b) {
 		return false
@@ -180,6 +210,9 @@ const (
 	escapedSeparator = "~1"
 	tilde            = "~"
 	escapedTilde     = "~0"
+
+	wildcardOne = "*"
+	wildcardAny = "**"
 )
 
 func unescapeToken(tok string) string {
@@ -401,3 +401,39 @@ func TestPointerLessThan(t *testing.T) {
 		})
 	}
 }
+
+func TestPointerMatches(t *testing.T) {
+	tt := []struct {
+		pointer string
+		pattern string
+
+		want bool
+	}{
+		{pointer: "", pattern: "", want: true},
+		{pointer: "/foo", pattern: "", want: false},
+		{pointer: "", pattern: "/foo", want: false},
+		{pointer: "/foo", pattern: "/foo", want: true},
+		{pointer: "/foo", pattern: "/bar", want: false},
+		{pointer: "/foo/bar", pattern: "/foo", want: false},
+		{pointer: "/foo/bar", pattern: "/foo/*", want: true},
+		{pointer: "/foo", pattern: "/foo/*", want: false},
+		{pointer: "/foo/1/bar", pattern: "/foo/*/bar", want: true},
+		{pointer: "/foo/1/baz", pattern: "/foo/*/bar", want: false},
+		{pointer: "", pattern: "/**", want: true},
+		{pointer: "/foo/1/bar", pattern: "/**", want: true},
+		{pointer: "/foo/1/bar", pattern: "/**/bar", want: true},
+		{pointer: "/bar", pattern: "/**/bar", want: true},
+		{pointer: "/foo/1/baz", pattern: "/**/bar", want: false},
+		{pointer: "/foo/1/bar/baz", pattern: "/foo/**/baz", want: true},
+		{pointer: "/a~1b", pattern: "/a~1b", want: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.pointer+" matches "+tc.pattern, func(t *testing.T) {
+			pointer := jsonpointer.NewPointerFromPath(tc.pointer)
+			pattern := jsonpointer.NewPointerFromPath(tc.pattern)
+
+			require.Equal(t, tc.want, pointer.Matches(pattern))
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package jsondiffprinter
+
+import (
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpatch"
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+// A Normalizer defines, which values are considered semantically equivalent,
+// even though they are not strictly equal. Changes between equivalent values
+// are treated as noise and printed as unchanged.
+//
+// Normalizers are created with UnorderedArrays, NumericTolerance,
+// EmptyAsMissing, TrimWhitespace, CaseInsensitiveKeys and IgnorePaths and are
+// passed to the formatter with WithNormalizers.
+type Normalizer struct {
+	patterns []jsonpointer.Pointer
+	equal    func(e equivalence, path jsonpointer.Pointer, a, b any) (equal bool, ok bool)
+	// caseInsensitiveKeys is true, if the keys of the objects at the matching
+	// paths are compared case-insensitively.
+	caseInsensitiveKeys bool
+}
+
+func newNormalizer(patterns []string, equal func(e equivalence, path jsonpointer.Pointer, a, b any) (bool, bool)) Normalizer {
+	n := Normalizer{
+		patterns: make([]jsonpointer.Pointer, 0, len(patterns)),
+		equal:    equal,
+	}
+	for _, pattern := range patterns {
+		n.patterns = append(n.patterns, jsonpointer.NewPointerFromPath(pattern))
+	}
+	return n
+}
+
+func (n Normalizer) matches(path jsonpointer.Pointer) bool {
+	if len(n.patterns) == 0 {
+		return true
+	}
+	for _, pattern := range n.patterns {
+		if path.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnorderedArrays returns a normalizer, which treats the arrays at the paths
+// matching the given patterns as sets, that is, the order of the elements is
+// ignored.
+// The patterns follow the same syntax as for WithValueRenderer. If no pattern
+// is provided, the normalizer applies to all arrays.
+func UnorderedArrays(patterns ...string) Normalizer {
+	return newNormalizer(patterns, func(e equivalence, path jsonpointer.Pointer, a, b any) (bool, bool) {
+		aa, aok := a.([]any)
+		ba, bok := b.([]any)
+		if !aok || !bok {
+			return false, false
+		}
+		if len(aa) != len(ba) {
+			return false, true
+		}
+
+		matched := make([]bool, len(ba))
+	outer:
+		for i := range aa {
+			for j := range ba {
+				if matched[j] {
+					continue
+				}
+				if e.equal(path.AppendIndex(i), aa[i], ba[j]) {
+					matched[j] = true
+					continue outer
+				}
+			}
+			return false, true
+		}
+		return true, true
+	})
+}
+
+// NumericTolerance returns a normalizer, which treats numbers at the paths
+// matching the given patterns as equal, if they differ by no more than
+// epsilon.
+// The patterns follow the same syntax as for WithValueRenderer. If no pattern
+// is provided, the normalizer applies to all numbers.
+func NumericTolerance(epsilon float64, patterns ...string) Normalizer {
+	return newNormalizer(patterns, func(_ equivalence, _ jsonpointer.Pointer, a, b any) (bool, bool) {
+		af, aok := a.(float64)
+		bf, bok := b.(float64)
+		if !aok || !bok {
+			return false, false
+		}
+		return math.Abs(af-bf) <= epsilon, true
+	})
+}
+
+// EmptyAsMissing returns a normalizer, which treats the empty string, null and
+// a missing value at the paths matching the given patterns as equal.
+// The patterns follow the same syntax as for WithValueRenderer. If no pattern
+// is provided, the normalizer applies to all values.
+func EmptyAsMissing(patterns ...string) Normalizer {
+	return newNormalizer(patterns, func(_ equivalence, _ jsonpointer.Pointer, a, b any) (bool, bool) {
+		if !isEmpty(a) || !isEmpty(b) {
+			return false, false
+		}
+		return true, true
+	})
+}
+
+func isEmpty(v any) bool {
+	switch vt := v.(type) {
+	case nil, missingValue:
+		return true
+	case string:
+		return vt == ""
+	default:
+		return false
+	}
+}
+
+// TrimWhitespace returns a normalizer, which ignores leading and trailing
+// whitespace when comparing strings at the paths matching the given patterns.
+// The patterns follow the same syntax as for WithValueRenderer. If no pattern
+// is provided, the normalizer applies to all strings.
+func TrimWhitespace(patterns ...string) Normalizer {
+	return newNormalizer(patterns, func(_ equivalence, _ jsonpointer.Pointer, a, b any) (bool, bool) {
+		as, aok := a.(string)
+		bs, bok := b.(string)
+		if !aok || !bok {
+			return false, false
+		}
+		return strings.TrimSpace(as) == strings.TrimSpace(bs), true
+	})
+}
+
+// CaseInsensitiveKeys returns a normalizer, which compares the keys of the
+// objects at the paths matching the given patterns case-insensitively, e.g.
+// {"Name": "web"} and {"name": "web"} are equal. If a key matches several keys
+// of the other object, which only differ in case, the keys are compared
+// exactly.
+// The patterns follow the same syntax as for WithValueRenderer. If no pattern
+// is provided, the normalizer applies to all objects.
+func CaseInsensitiveKeys(patterns ...string) Normalizer {
+	n := newNormalizer(patterns, nil)
+	n.caseInsensitiveKeys = true
+	return n
+}
+
+// IgnorePaths returns a normalizer, which treats all values at the paths
+// matching the given patterns as equal, that is, changes at these paths are
+// never reported.
+// The patterns follow the same syntax as for WithValueRenderer. If no pattern
+// is provided, nothing is ignored.
+func IgnorePaths(patterns ...string) Normalizer {
+	return newNormalizer(patterns, func(_ equivalence, _ jsonpointer.Pointer, _, _ any) (bool, bool) {
+		return true, len(patterns) > 0
+	})
+}
+
+// missingValue represents the absence of a value, e.g. an object key, which
+// only exists on one side of the comparison.
+type missingValue struct{}
+
+type equivalence struct {
+	normalizers []Normalizer
+}
+
+// equal reports whether a and b, located at path, are semantically equivalent
+// according to the normalizers.
+func (e equivalence) equal(path jsonpointer.Pointer, a, b any) bool {
+	a, b = plainValue(a), plainValue(b)
+
+	for _, n := range e.normalizers {
+		if n.equal == nil || !n.matches(path) {
+			continue
+		}
+		if equal, ok := n.equal(e, path, a, b); ok {
+			return equal
+		}
+	}
+
+	switch at := a.(type) {
+	case map[string]any:
+		bt, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		if e.caseInsensitiveKeys(path) {
+			bt = matchKeyCase(at, bt)
+		}
+		for k := range at {
+			if _, ok := bt[k]; !ok {
+				if !e.equal(path.AppendKey(k), at[k], missingValue{}) {
+					return false
+				}
+			}
+		}
+		for k, bv := range bt {
+			av, ok := at[k]
+			if !ok {
+				av = missingValue{}
+			}
+			if !e.equal(path.AppendKey(k), av, bv) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		bt, ok := b.([]any)
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for i := range at {
+			if !e.equal(path.AppendIndex(i), at[i], bt[i]) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// caseInsensitiveKeys reports whether the keys of the object at path are
+// compared case-insensitively.
+func (e equivalence) caseInsensitiveKeys(path jsonpointer.Pointer) bool {
+	for _, n := range e.normalizers {
+		if n.caseInsensitiveKeys && n.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchKeyCase returns a copy of b, in which the keys, that only exist in b
+// and only differ in case from a key, that only exists in a, are renamed to
+// the key of a. Keys without an unambiguous counterpart are kept as is.
+func matchKeyCase(a, b map[string]any) map[string]any {
+	counterpart := func(key string, from, to map[string]any) (string, bool) {
+		var found string
+		var n int
+		for k := range to {
+			if _, ok := from[k]; ok || !strings.EqualFold(k, key) {
+				continue
+			}
+			found = k
+			n++
+		}
+		return found, n == 1
+	}
+
+	matched := make(map[string]any, len(b))
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			ak, ok := counterpart(k, b, a)
+			if ok {
+				// The key of a needs to match only this key of b.
+				if bk, ok := counterpart(ak, a, b); ok && bk == k {
+					k = ak
+				}
+			}
+		}
+		matched[k] = v
+	}
+	return matched
+}
+
+func plainValue(v any) any {
+	switch vt := v.(type) {
+	case jsonInJSONObject:
+		return map[string]any(vt)
+	case jsonInJSONArray:
+		return []any(vt)
+	default:
+		return v
+	}
+}
+
+// normalize replaces all changes in the diff patch series, which are
+// equivalent according to the normalizers of the formatter, with the
+// respective unchanged values.
+func (f formatter) normalize(diff jsonpatch.Patch) (jsonpatch.Patch, error) {
+	if len(f.normalizers) == 0 {
+		return diff, nil
+	}
+
+	e := equivalence{normalizers: f.normalizers}
+	normalized := make(jsonpatch.Patch, 0, len(diff))
+
+	for i := 0; i < len(diff); i++ {
+		end := subtreeEnd(diff, i)
+		if !hasChange(diff[i:end]) {
+			normalized = append(normalized, diff[i:end]...)
+			i = end - 1
+			continue
+		}
+
+		before, hasBefore := reconstructValue(diff[i:end], true)
+		after, hasAfter := reconstructValue(diff[i:end], false)
+		if !hasBefore {
+			before = missingValue{}
+		}
+		if !hasAfter {
+			after = missingValue{}
+		}
+
+		if !e.equal(diff[i].Path, before, after) {
+			// Not equivalent as a whole, continue with the children.
+			normalized = append(normalized, diff[i])
+			continue
+		}
+
+		unchanged := before
+		if !hasBefore {
+			unchanged = after
+		}
+		series, err := f.asPatchTestSeries(unchanged, diff[i].Path)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, series...)
+		i = end - 1
+	}
+
+	return normalized, nil
+}
+
+// subtreeEnd returns the index following the last descendant of the operation
+// at index i.
+func subtreeEnd(diff jsonpatch.Patch, i int) int {
+	end := i + 1
+	for ; end < len(diff); end++ {
+		if !diff[i].Path.IsAncestorOf(diff[end].Path) {
+			break
+		}
+	}
+	return end
+}
+
+func hasChange(diff jsonpatch.Patch) bool {
+	for _, op := range diff {
+		if op.Operation != jsonpatch.OperationTest {
+			return true
+		}
+	}
+	return false
+}
+
+// reconstructValue returns the value before (or after) the change for the
+// subtree, which is formed by the first operation of diff and its
+// descendants. If the value does not exist on the requested side, false is
+// returned.
+func reconstructValue(diff jsonpatch.Patch, before bool) (any, bool) {
+	op := diff[0]
+	switch op.Operation {
+	case jsonpatch.OperationAdd:
+		return op.Value, !before
+	case jsonpatch.OperationRemove:
+		return op.OldValue, before
+	case jsonpatch.OperationReplace:
+		if before {
+			return op.OldValue, true
+		}
+		return op.Value, true
+	}
+
+	switch op.Value.(type) {
+	case map[string]any, jsonInJSONObject:
+		m := make(map[string]any)
+		for i := 1; i < len(diff); {
+			end := subtreeEnd(diff, i)
+			if v, ok := reconstructValue(diff[i:end], before); ok {
+				m[diff[i].Path[len(diff[i].Path)-1]] = v
+			}
+			i = end
+		}
+		if _, ok := op.Value.(jsonInJSONObject); ok {
+			return jsonInJSONObject(m), true
+		}
+		return m, true
+
+	case []any, jsonInJSONArray:
+		a := make([]any, 0, len(diff))
+		for i := 1; i < len(diff); {
+			end := subtreeEnd(diff, i)
+			if v, ok := reconstructValue(diff[i:end], before); ok {
+				a = append(a, v)
+			}
+			i = end
+		}
+		if _, ok := op.Value.(jsonInJSONArray); ok {
+			return jsonInJSONArray(a), true
+		}
+		return a, true
+
+	default:
+		return op.Value, true
+	}
+}
@@ -1,13 +1,22 @@
 package jsondiffprinter
 
-import "io"
+import (
+	"io"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
 
 // Option is a function that sets an option on the formatter.
 type Option func(*formatter)
 
+// WithTerraformDefaults provides an option for the formatter to print the
+// diff in the style of Terraform plans.
+// Keys and values are printed as HCL, e.g. multi-line strings as heredoc.
+// This is best-effort: the output resembles HCL, but it is not guaranteed to
+// be valid HCL, even with the diff markers removed.
+// Colorful output is not enabled by this option, use WithColor to enable it.
 func WithTerraformDefaults() Option {
 	return func(f *formatter) {
-		f.c.disable = false
 		f.indentation = "    "
 		f.indentedDiffMarkers = true
 		f.commas = false
@@ -20,6 +29,7 @@ func WithTerraformDefaults() Option {
 		f.omitChangeIndicatorOnEmptyKey = true
 		f.jsonInJSONStart = jsonInJSONStartTerraform
 		f.jsonInJSONEnd = jsonInJSONEndTerraform
+		f.hcl = true
 	}
 }
 
@@ -90,3 +100,118 @@ func WithPatchSeriesPostProcess(patchSeriesPostProcess PatchSeriesPostProcessor)
 		f.patchSeriesPostProcess = patchSeriesPostProcess
 	}
 }
+
+// WithValueRenderer provides an option for the formatter to render scalar
+// values at paths matching pattern with the given renderer instead of the
+// default JSON encoding.
+// The pattern is a JSON pointer, where the token "*" matches exactly one
+// arbitrary token and "**" matches any number of arbitrary tokens, e.g.
+// "/alerts/*/thresholdMs" or "/**/timestamp".
+// If multiple renderers match a path, they are consulted in the order they
+// have been provided.
+func WithValueRenderer(pattern string, renderer ValueRenderer) Option {
+	return func(f *formatter) {
+		f.valueRenderers = append(f.valueRenderers, valueRenderer{
+			pattern: jsonpointer.NewPointerFromPath(pattern),
+			render:  renderer,
+		})
+	}
+}
+
+// WithNormalizers provides an option for the formatter to set the normalizers,
+// which are used to detect changes between semantically equivalent values.
+// Such changes are considered noise and are printed as unchanged.
+func WithNormalizers(normalizers ...Normalizer) Option {
+	return func(f *formatter) {
+		f.normalizers = append(f.normalizers, normalizers...)
+	}
+}
+
+// WithUnknownPaths provides an option for the formatter to mark the values at
+// paths matching one of the patterns as unknown, e.g. the values of a
+// Terraform plan, which are only known after apply. Unknown values are always
+// considered changed and are rendered using the unknown placeholder. Objects
+// and arrays containing unknown values are printed as replaced.
+// The patterns follow the syntax of WithValueRenderer. Since the unknown
+// values replace values of the after document, the paths need to exist in the
+// after document, typically with the value null.
+func WithUnknownPaths(patterns ...string) Option {
+	return func(f *formatter) {
+		for _, pattern := range patterns {
+			f.unknownPaths = append(f.unknownPaths, jsonpointer.NewPointerFromPath(pattern))
+		}
+	}
+}
+
+// WithUnknownPlaceholder provides an option for the formatter to set the
+// placeholder, which is printed for unknown values.
+// If not set, "(known after apply)" is used.
+func WithUnknownPlaceholder(placeholder string) Option {
+	return func(f *formatter) {
+		f.unknownPlaceholder = placeholder
+	}
+}
+
+// WithBlock provides an option for the formatter to enclose the diff in a
+// named block, e.g. a Terraform resource. The block is preceded by a comment
+// containing the address of the block and the description of the action.
+// The symbol of the action replaces the diff marker of the root value.
+func WithBlock(block Block) Option {
+	return func(f *formatter) {
+		f.block = &block
+	}
+}
+
+// WithBlockPaths provides an option for the formatter to print the arrays of
+// objects at paths matching one of the patterns as repeated nested blocks
+// named after the key of the array, e.g. "ebs_block_device { ... }", instead
+// of "ebs_block_device = [ { ... } ]". This is how Terraform renders nested
+// blocks, so this option is intended to be used together with
+// WithTerraformDefaults. Unchanged blocks are hidden like unchanged attributes.
+// The patterns follow the syntax of WithValueRenderer.
+func WithBlockPaths(patterns ...string) Option {
+	return func(f *formatter) {
+		for _, pattern := range patterns {
+			f.blockPaths = append(f.blockPaths, jsonpointer.NewPointerFromPath(pattern))
+		}
+	}
+}
+
+// WithSetPaths provides an option for the formatter to print the arrays at
+// paths matching one of the patterns as sets, e.g. "toset([ ... ])" in the
+// style of Terraform.
+// The patterns follow the syntax of WithValueRenderer.
+func WithSetPaths(patterns ...string) Option {
+	return func(f *formatter) {
+		for _, pattern := range patterns {
+			f.collectionHints = append(f.collectionHints, collectionHint{
+				pattern:   jsonpointer.NewPointerFromPath(pattern),
+				valueType: valueTypeSet,
+			})
+		}
+	}
+}
+
+// WithListPaths provides an option for the formatter to print the arrays at
+// paths matching one of the patterns as lists, e.g. "tolist([ ... ])" in the
+// style of Terraform.
+// The patterns follow the syntax of WithValueRenderer.
+func WithListPaths(patterns ...string) Option {
+	return func(f *formatter) {
+		for _, pattern := range patterns {
+			f.collectionHints = append(f.collectionHints, collectionHint{
+				pattern:   jsonpointer.NewPointerFromPath(pattern),
+				valueType: valueTypeList,
+			})
+		}
+	}
+}
+
+// WithSummary provides an option for the formatter to enable or disable
+// the summary, which is printed after the diff and contains the number of
+// added, removed, replaced and unchanged values.
+func WithSummary(summary bool) Option {
+	return func(f *formatter) {
+		f.summary = summary
+	}
+}
@@ -235,7 +235,7 @@ func (f formatter) compileDiffPatchSeries(src jsonpatch.Patch, patch jsonpatch.P
 			}
 
 			for j := i; j < len(src); j++ {
-				if patchOp.Path.IsParentOf(src[j].Path) {
+				if patchOp.Path.IsAncestorOf(src[j].Path) {
 					src = slices.Delete(src, j, j+1)
 					j--
 				}
@@ -314,7 +314,7 @@ func (f formatter) compileDiffPatchSeries(src jsonpatch.Patch, patch jsonpatch.P
 			}
 
 			for j := i; j < len(src); j++ {
-				if patchOp.Path.IsParentOf(src[j].Path) {
+				if patchOp.Path.IsAncestorOf(src[j].Path) {
 					src = slices.Delete(src, j, j+1)
 					j--
 				}
@@ -0,0 +1,90 @@
+package jsondiffprinter
+
+import (
+	"fmt"
+
+	"github.com/breml/jsondiffprinter/internal/jsonpatch"
+)
+
+// Stats contains the number of changed and unchanged values of a diff.
+type Stats struct {
+	Added     Count
+	Removed   Count
+	Replaced  Count
+	Unchanged Count
+}
+
+// Count is the number of values of a given kind of change, separated by
+// leaves (strings, numbers, booleans and null) and containers (objects and
+// arrays).
+// Containers, whose content has changed, are not counted themselves, only the
+// changes within them are counted.
+type Count struct {
+	Leaves     int
+	Containers int
+}
+
+// Total returns the sum of leaves and containers.
+func (c Count) Total() int {
+	return c.Leaves + c.Containers
+}
+
+func (c *Count) add(container bool) {
+	if container {
+		c.Containers++
+		return
+	}
+	c.Leaves++
+}
+
+// String returns the summary of the stats in the form
+// "Summary: 1 added, 2 removed, 0 replaced, 5 unchanged."
+func (s Stats) String() string {
+	return fmt.Sprintf("Summary: %d added, %d removed, %d replaced, %d unchanged.",
+		s.Added.Total(), s.Removed.Total(), s.Replaced.Total(), s.Unchanged.Total())
+}
+
+func computeStats(diff jsonpatch.Patch) Stats {
+	var stats Stats
+
+	for i, op := range diff {
+		switch op.Operation {
+		case jsonpatch.OperationAdd:
+			stats.Added.add(isContainer(op.Value))
+		case jsonpatch.OperationRemove:
+			stats.Removed.add(isContainer(op.OldValue))
+		case jsonpatch.OperationReplace:
+			stats.Replaced.add(isContainer(op.Value))
+		case jsonpatch.OperationTest:
+			if !isContainer(op.Value) {
+				stats.Unchanged.Leaves++
+				continue
+			}
+			if hasChange(diff[i:subtreeEnd(diff, i)]) {
+				continue
+			}
+			stats.Unchanged.Containers++
+		}
+	}
+
+	return stats
+}
+
+func changedPaths(diff jsonpatch.Patch) []string {
+	var paths []string
+	for _, op := range diff {
+		if op.Operation != jsonpatch.OperationTest {
+			paths = append(paths, op.Path.String())
+		}
+	}
+	return paths
+}
+
+func isContainer(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any, jsonInJSONObject, jsonInJSONArray:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package jsondiffprinter
+
+import (
+	"github.com/breml/jsondiffprinter/internal/jsonpatch"
+	"github.com/breml/jsondiffprinter/internal/jsonpointer"
+)
+
+const defaultUnknownPlaceholder = "(known after apply)"
+
+// unknownValue represents a value in the after document, which is not yet
+// known, e.g. because it is computed when a Terraform plan is applied.
+type unknownValue struct{}
+
+// markUnknown replaces the values in the diff patch series, which are located
+// at the paths matching the unknown paths of the formatter, with unknown
+// values. Since unknown values are always considered to be changed, unchanged
+// values become replace operations and their descendants are removed. The
+// objects and arrays containing unknown values are marked as replaced.
+func (f formatter) markUnknown(diff jsonpatch.Patch) jsonpatch.Patch {
+	if len(f.unknownPaths) == 0 {
+		return diff
+	}
+
+	marked := make(jsonpatch.Patch, 0, len(diff))
+	var unknownPaths []jsonpointer.Pointer
+	for i := 0; i < len(diff); i++ {
+		op := diff[i]
+		switch op.Operation {
+		case jsonpatch.OperationTest:
+			if !f.isUnknown(op.Path) {
+				break
+			}
+			end := subtreeEnd(diff, i)
+			before, _ := reconstructValue(diff[i:end], true)
+			op = op.Clone()
+			op.Operation = jsonpatch.OperationReplace
+			op.OldValue = before
+			op.Value = unknownValue{}
+			i = end - 1
+
+		case jsonpatch.OperationAdd, jsonpatch.OperationReplace:
+			value := f.replaceUnknown(op.Value, op.Path)
+			if _, ok := value.(unknownValue); !ok && !hasUnknown(value) {
+				break
+			}
+			op = op.Clone()
+			op.Value = value
+		}
+
+		if op.Operation != jsonpatch.OperationTest && (f.isUnknown(op.Path) || hasUnknown(op.Value)) {
+			unknownPaths = append(unknownPaths, op.Path)
+		}
+		marked = append(marked, op)
+	}
+
+	for i := range marked {
+		if marked[i].Operation != jsonpatch.OperationTest || marked[i].Path.IsEmpty() {
+			continue
+		}
+		for _, path := range unknownPaths {
+			if !marked[i].Path.IsAncestorOf(path) {
+				continue
+			}
+			marked[i] = marked[i].Clone()
+			if marked[i].Metadata["operationOverride"] == "" {
+				marked[i].Metadata["operationOverride"] = string(jsonpatch.OperationReplace)
+			}
+			break
+		}
+	}
+
+	return marked
+}
+
+func (f formatter) isUnknown(path jsonpointer.Pointer) bool {
+	for _, pattern := range f.unknownPaths {
+		if path.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceUnknown returns a copy of v, located at path, where all the values at
+// unknown paths are replaced with unknown values.
+func (f formatter) replaceUnknown(v any, path jsonpointer.Pointer) any {
+	if f.isUnknown(path) {
+		return unknownValue{}
+	}
+
+	switch vt := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(vt))
+		for k, v := range vt {
+			m[k] = f.replaceUnknown(v, path.AppendKey(k))
+		}
+		return m
+	case []any:
+		a := make([]any, len(vt))
+		for i, v := range vt {
+			a[i] = f.replaceUnknown(v, path.AppendIndex(i))
+		}
+		return a
+	default:
+		return v
+	}
+}
+
+func hasUnknown(v any) bool {
+	switch vt := v.(type) {
+	case unknownValue:
+		return true
+	case map[string]any:
+		for _, v := range vt {
+			if hasUnknown(v) {
+				return true
+			}
+		}
+	case []any:
+		for _, v := range vt {
+			if hasUnknown(v) {
+				return true
+			}
+		}
+	}
+	return false
+}